@@ -0,0 +1,215 @@
+package sc
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// BreakerClosed lets the requests go through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects the requests until OpenTimeout elapses
+	BreakerOpen
+	// BreakerHalfOpen lets limited trial requests go through
+	BreakerHalfOpen
+)
+
+// Define the defaults of the circuit breaker
+const (
+	DefaultBreakerFailureThreshold = 5
+	DefaultBreakerOpenTimeout      = 30 * time.Second
+	DefaultBreakerHalfOpenRequests = 1
+)
+
+// BreakerState is the state of the circuit breaker of a service center address
+type BreakerState int
+
+// String returns the name of the state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerOptions configures the circuit breaker of each service center address
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failures opening the breaker
+	FailureThreshold int
+	// SlowThreshold counts a call slower than it as a failure, zero disables it
+	SlowThreshold time.Duration
+	// OpenTimeout is how long the breaker stays open before the trial requests
+	OpenTimeout time.Duration
+	// HalfOpenRequests is the number of concurrent trial requests in half-open state
+	HalfOpenRequests int
+	// OnStateChange is called when the breaker of an address changes its state
+	OnStateChange func(address string, from, to BreakerState)
+}
+
+// CircuitOpenError is returned when the breaker of the address rejects the request
+type CircuitOpenError struct {
+	Address string
+}
+
+// Error gets the Error message from the Error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of service center %s is open", e.Address)
+}
+
+type breaker struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trials   int
+}
+
+// breakers keeps one breaker per service center address
+type breakers struct {
+	mutex    sync.Mutex
+	opt      CircuitBreakerOptions
+	breakers map[string]*breaker
+}
+
+func newBreakers(opt *CircuitBreakerOptions) *breakers {
+	if opt == nil {
+		return nil
+	}
+	o := *opt
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = DefaultBreakerFailureThreshold
+	}
+	if o.OpenTimeout <= 0 {
+		o.OpenTimeout = DefaultBreakerOpenTimeout
+	}
+	if o.HalfOpenRequests <= 0 {
+		o.HalfOpenRequests = DefaultBreakerHalfOpenRequests
+	}
+	return &breakers{opt: o, breakers: make(map[string]*breaker)}
+}
+
+func (bs *breakers) get(address string) *breaker {
+	b, ok := bs.breakers[address]
+	if !ok {
+		b = &breaker{}
+		bs.breakers[address] = b
+	}
+	return b
+}
+
+// transit must be called with the lock held, the callback is returned to be called without the lock
+func (bs *breakers) transit(address string, b *breaker, to BreakerState) func() {
+	from := b.state
+	if from == to {
+		return nil
+	}
+	b.state = to
+	b.failures = 0
+	b.trials = 0
+	if to == BreakerOpen {
+		b.openedAt = time.Now()
+	}
+	if bs.opt.OnStateChange == nil {
+		return nil
+	}
+	return func() {
+		bs.opt.OnStateChange(address, from, to)
+	}
+}
+
+// available reports whether a request to the address would be allowed, nothing is changed
+func (bs *breakers) available(address string) bool {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	b, ok := bs.breakers[address]
+	if !ok {
+		return true
+	}
+	switch b.state {
+	case BreakerOpen:
+		return time.Since(b.openedAt) >= bs.opt.OpenTimeout
+	case BreakerHalfOpen:
+		return b.trials < bs.opt.HalfOpenRequests
+	}
+	return true
+}
+
+// allow is called before a request, an open breaker turns half-open after OpenTimeout
+func (bs *breakers) allow(address string) bool {
+	bs.mutex.Lock()
+	b := bs.get(address)
+	var notify func()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= bs.opt.OpenTimeout {
+		notify = bs.transit(address, b, BreakerHalfOpen)
+	}
+	allowed := true
+	switch b.state {
+	case BreakerOpen:
+		allowed = false
+	case BreakerHalfOpen:
+		if b.trials >= bs.opt.HalfOpenRequests {
+			allowed = false
+		} else {
+			b.trials++
+		}
+	}
+	bs.mutex.Unlock()
+	if notify != nil {
+		notify()
+	}
+	return allowed
+}
+
+// done records the result of a request allowed by allow
+func (bs *breakers) done(address string, err error, latency time.Duration) {
+	failed := err != nil || (bs.opt.SlowThreshold > 0 && latency > bs.opt.SlowThreshold)
+	bs.mutex.Lock()
+	b := bs.get(address)
+	var notify func()
+	switch b.state {
+	case BreakerClosed:
+		if !failed {
+			b.failures = 0
+			break
+		}
+		b.failures++
+		if b.failures >= bs.opt.FailureThreshold {
+			notify = bs.transit(address, b, BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		if failed {
+			notify = bs.transit(address, b, BreakerOpen)
+		} else {
+			notify = bs.transit(address, b, BreakerClosed)
+		}
+	}
+	bs.mutex.Unlock()
+	if notify != nil {
+		notify()
+	}
+}
+
+// release gives back the trial taken by allow without a result, it is used for the canceled requests
+func (bs *breakers) release(address string) {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	if b := bs.get(address); b.state == BreakerHalfOpen && b.trials > 0 {
+		b.trials--
+	}
+}
+
+// state returns the state of the address
+func (bs *breakers) state(address string) BreakerState {
+	bs.mutex.Lock()
+	defer bs.mutex.Unlock()
+	b, ok := bs.breakers[address]
+	if !ok {
+		return BreakerClosed
+	}
+	return b.state
+}
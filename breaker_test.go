@@ -0,0 +1,61 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestOptions_CircuitBreaker(t *testing.T) {
+	var healthy int32
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.LoadInt32(&healthy) == 0 {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writer.Write([]byte(`{"services":[]}`))
+	}))
+	defer scServer.Close()
+
+	var mutex sync.Mutex
+	var transitions []string
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{scServer.Listener.Addr().String()},
+		CircuitBreaker: &sc.CircuitBreakerOptions{
+			FailureThreshold: 2,
+			OpenTimeout:      100 * time.Millisecond,
+			OnStateChange: func(address string, from, to sc.BreakerState) {
+				mutex.Lock()
+				defer mutex.Unlock()
+				transitions = append(transitions, from.String()+"->"+to.String())
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Run("consecutive failures should open the breaker", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err = c.GetAllMicroServices()
+			assert.Error(t, err)
+		}
+		_, err = c.GetAllMicroServices()
+		_, ok := err.(*sc.CircuitOpenError)
+		assert.True(t, ok)
+	})
+	t.Run("successful trial should close the breaker", func(t *testing.T) {
+		atomic.StoreInt32(&healthy, 1)
+		time.Sleep(150 * time.Millisecond)
+		_, err = c.GetAllMicroServices()
+		assert.NoError(t, err)
+		mutex.Lock()
+		defer mutex.Unlock()
+		assert.Equal(t, []string{"closed->open", "open->half-open", "half-open->closed"}, transitions)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/url"
 	"os"
@@ -14,13 +15,11 @@ import (
 	"sync"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
 	"github.com/go-chassis/cari/addresspool"
 	"github.com/go-chassis/cari/discovery"
 	"github.com/go-chassis/cari/rbac"
 	"github.com/go-chassis/foundation/httpclient"
 	"github.com/go-chassis/foundation/httputil"
-	"github.com/go-chassis/openlog"
 	"github.com/gorilla/websocket"
 	"github.com/patrickmn/go-cache"
 )
@@ -37,6 +36,7 @@ const (
 	StatusPath             = "/status"
 	DependencyPath         = "/dependencies"
 	PropertiesPath         = "/properties"
+	TagsPath               = "/tags"
 	TokenPath              = "/v4/token"
 	ReadinessPath          = "/health/readiness"
 	HeaderContentType      = "Content-Type"
@@ -65,24 +65,52 @@ var (
 	ErrMicroServiceExists = errors.New("micro-service already exists")
 	// ErrMicroServiceNotExists means service is not exists
 	ErrMicroServiceNotExists = errors.New("micro-service does not exist")
+	// ErrMicroServiceInstanceNotExists means instance is not exists
+	ErrMicroServiceInstanceNotExists = errors.New("micro-service instance does not exist")
+	// ErrSchemaNotExists means schema is not exists
+	ErrSchemaNotExists = errors.New("schema does not exist")
 	// ErrEmptyCriteria means you gave an empty list of criteria
 	ErrEmptyCriteria = errors.New("batch find criteria is empty")
 	ErrNil           = errors.New("input is nil")
+	// ErrNoMembers means service center reports no member of the cluster
+	ErrNoMembers = errors.New("service center reports no member")
 )
 
 // Client communicate to Service-Center
 type Client struct {
-	opt      Options
-	client   *httpclient.Requests
-	protocol string
-	watchers map[string]bool
-	mutex    sync.Mutex
+	opt       Options
+	transport Transport
+	protocol  string
+	watchers  map[string]bool
+	mutex     sync.Mutex
 	// addresspool mutex
 	poolMutex sync.Mutex
-	wsDialer  *websocket.Dialer
 	// record the websocket connection with the service center
-	conns map[string]*websocket.Conn
+	conns *connManager
 	pool  *addresspool.Pool
+	log   Logger
+	// reconnector retries the broken websocket connections
+	reconnector *ReconnectScheduler
+	limiter     *rateLimiter
+	breakers    *breakers
+	// flights collapses the concurrent identical reads
+	flights  flightGroup
+	snapshot *snapshotStore
+	// static are the configured or resolved same AZ addresses,
+	// members are the addresses of the service center cluster learned by SyncEndpoints
+	static       []string
+	members      []string
+	membersMutex sync.RWMutex
+	// resolved identifies the last applied resolution, stopResolver stops the endpoint resolver
+	resolved     string
+	stopResolver context.CancelFunc
+	// endpoints records the health of the service center addresses
+	endpoints *endpointTracker
+	// patchLocks serializes the property patches of the same service or instance
+	patchLocks keyedMutex
+	// ctx is canceled when the client is closed
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func (c *Client) dialWebsocket(url *url.URL) (*websocket.Conn, *http.Response, error) {
@@ -90,29 +118,34 @@ func (c *Client) dialWebsocket(url *url.URL) (*websocket.Conn, *http.Response, e
 	handshakeReq := &http.Request{Header: c.GetDefaultHeaders(), URL: url}
 	if c.opt.SignRequest != nil {
 		if err = c.opt.SignRequest(handshakeReq); err != nil {
-			openlog.Error("sign websocket request failed" + err.Error())
+			c.log.Error("sign websocket request failed", "url", url.Path, "error", err)
 			return nil, nil, err
 		}
 	} else if httpclient.SignRequest != nil {
 		if err = httpclient.SignRequest(handshakeReq); err != nil {
-			openlog.Error("sign websocket request failed" + err.Error())
+			c.log.Error("sign websocket request failed", "url", url.Path, "error", err)
 			return nil, nil, err
 		}
 	}
 
-	return c.wsDialer.Dial(url.String(), handshakeReq.Header)
-}
-
-type PeerStatusResp struct {
-	Peers []*Peer `json:"peers"`
-}
-
-type Peer struct {
-	Name      string   `json:"name"`
-	Kind      string   `json:"kind"`
-	Mode      []string `json:"mode"`
-	Endpoints []string `json:"endpoints"`
-	Status    string   `json:"status"`
+	if c.breakers != nil && !c.breakers.allow(url.Host) {
+		return nil, nil, &CircuitOpenError{Address: url.Host}
+	}
+	start := time.Now()
+	conn, resp, err := c.transport.DialWebsocket(c.ctx, url.String(), handshakeReq.Header)
+	dialErr := err
+	if resp != nil && resp.StatusCode < http.StatusInternalServerError {
+		// the handshake is rejected by a healthy service center
+		dialErr = nil
+	}
+	if c.breakers != nil {
+		c.breakers.done(url.Host, dialErr, time.Since(start))
+	}
+	c.endpoints.record(url.Host, dialErr, time.Since(start))
+	if err == nil {
+		c.endpoints.activate(url.Host)
+	}
+	return conn, resp, err
 }
 
 // URLParameter maintains the list of parameters to be added in URL
@@ -123,50 +156,88 @@ func NewClient(opt Options) (*Client, error) {
 	c := &Client{
 		opt:      opt,
 		watchers: make(map[string]bool),
-		conns:    make(map[string]*websocket.Conn),
-	}
-	options := c.buildClientOptions(opt)
+		log:      newLevelLogger(opt.Logger, opt.LogLevel),
+	}
+	c.conns = newConnManager(c.dialWebsocket, opt.WebsocketPingInterval, opt.WebsocketPongTimeout, c.log)
+	c.reconnector = opt.ReconnectScheduler
+	if c.reconnector == nil {
+		c.reconnector = DefaultReconnectScheduler
+	}
+	c.limiter = newRateLimiter(opt.RateLimit)
+	c.breakers = newBreakers(opt.CircuitBreaker)
+	c.endpoints = newEndpointTracker(opt.OnActiveAddressChange)
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	c.snapshot = newSnapshotStore(opt.Snapshot, c.log)
 	var err error
-	c.client, err = httpclient.New(options)
+	c.transport, err = c.newTransport(opt)
 	if err != nil {
+		c.cancel()
 		return nil, err
 	}
-	c.wsDialer = &websocket.Dialer{
-		TLSClientConfig: opt.TLSConfig,
-	}
 	c.protocol = "https"
 	if !c.opt.EnableSSL {
-		c.wsDialer = websocket.DefaultDialer
 		c.protocol = "http"
 	}
 	// Update the API Base Path based on the project
 	c.updateAPIPath()
-	c.pool = addresspool.NewPool(opt.Endpoints, addresspool.Options{
+	c.static = opt.Endpoints
+	if opt.EndpointResolver != nil {
+		if err = c.resolveEndpoints(c.ctx); err != nil {
+			if len(opt.Endpoints) == 0 {
+				c.cancel()
+				return nil, fmt.Errorf("resolve service center endpoints failed: %w", err)
+			}
+			c.log.Warn("resolve service center endpoints failed, use the configured endpoints", "error", err)
+		}
+	}
+	c.pool = addresspool.NewPool(c.sameAZAddresses(), addresspool.Options{
 		HttpProbeOptions: &addresspool.HttpProbeOptions{
 			Protocol: c.protocol,
 			Path:     MSAPIPath + ReadinessPath,
 		},
 		DiffAzEndpoints: opt.DiffAzEndpoints,
 	})
+	// the background tasks start after the last failure of the construction, so none of them leaks
+	if c.snapshot != nil {
+		go c.snapshot.run(c.ctx, opt.Snapshot.Interval)
+	}
+	if opt.EndpointResolver != nil {
+		var ctx context.Context
+		ctx, c.stopResolver = context.WithCancel(c.ctx)
+		go c.runResolver(ctx, opt.ResolveInterval)
+	}
+	if opt.SyncEndpointsInterval > 0 {
+		go c.runSyncEndpoints(opt.SyncEndpointsInterval)
+	}
+	if opt.EndpointProbeInterval > 0 {
+		go c.runEndpointProbes(opt.EndpointProbeInterval)
+	}
 	return c, nil
 }
 
-// Reset the service center client
+// Reset the service center client, the endpoint resolver is stopped and Options.Endpoints are used
 func (c *Client) Reset(opt Options) error {
 	c.poolMutex.Lock()
 	defer c.poolMutex.Unlock()
-	options := c.buildClientOptions(opt)
-	var err error
-	c.client, err = httpclient.New(options)
+	transport, err := c.newTransport(opt)
 	if err != nil {
 		return err
 	}
+	c.transport = transport
 	c.protocol = "https"
 	if !c.opt.EnableSSL {
-		c.wsDialer = websocket.DefaultDialer
 		c.protocol = "http"
 	}
-	c.pool.ResetAddress(opt.Endpoints)
+	c.membersMutex.Lock()
+	if c.stopResolver != nil {
+		// the endpoints given to Reset replace the resolved ones, so the resolver must not revert them
+		c.stopResolver()
+		c.stopResolver = nil
+		c.log.Info("endpoint resolver is stopped by reset")
+	}
+	c.static = opt.Endpoints
+	c.membersMutex.Unlock()
+	c.pool.ResetAddress(c.sameAZAddresses())
 	return nil
 }
 
@@ -203,8 +274,10 @@ func (c *Client) buildClientOptions(opt Options) *httpclient.Options {
 		} else {
 			token, err := c.GetToken(opt.AuthUser)
 			if err != nil {
+				c.log.Error("get token failed", "user", opt.AuthUser.Username, "error", err)
 				return err
 			}
+			c.log.Debug("token renewed", "user", opt.AuthUser.Username, "expiration", opt.TokenExpiration)
 			req.Header.Set(HeaderAuth, "Bearer "+token)
 			tokenCache.Set("token", token, cache.DefaultExpiration)
 		}
@@ -236,15 +309,79 @@ func (c *Client) SyncEndpoints() error {
 	defer c.poolMutex.Unlock()
 	instances, err := c.Health()
 	if err != nil {
+		c.log.Error("sync endpoints failed", "error", err)
 		return fmt.Errorf("sync SC ep failed. err:%s", err.Error())
 	}
-	return c.pool.SetAddressByInstances(instances)
+	members := memberAddresses(instances)
+	if len(members) == 0 {
+		// keep the current addresses, an empty pool is useless
+		c.log.Warn("sync endpoints got no member, keep the current endpoints")
+		return ErrNoMembers
+	}
+	c.membersMutex.Lock()
+	c.members = members
+	c.membersMutex.Unlock()
+	merged := c.sameAZAddresses()
+	c.log.Debug("sync endpoints", "members", len(members), "endpoints", len(merged))
+	c.pool.ResetAddress(merged)
+	return nil
+}
+
+// memberAddresses returns the host:port of the rest endpoints of the service center instances,
+// the other endpoints like grpc can not serve the http requests
+func memberAddresses(instances []*discovery.MicroServiceInstance) []string {
+	var addresses []string
+	for _, instance := range instances {
+		for _, endpoint := range instance.Endpoints {
+			u, err := url.Parse(endpoint)
+			if err != nil || u.Host == "" || u.Scheme != "rest" {
+				continue
+			}
+			addresses = append(addresses, u.Host)
+		}
+	}
+	return addresses
+}
+
+// mergeAddresses appends the addresses which are not in the list, the order is kept
+func mergeAddresses(list []string, more []string) []string {
+	seen := make(map[string]bool, len(list)+len(more))
+	merged := make([]string, 0, len(list)+len(more))
+	for _, addresses := range [][]string{list, more} {
+		for _, a := range addresses {
+			if !seen[a] {
+				seen[a] = true
+				merged = append(merged, a)
+			}
+		}
+	}
+	return merged
+}
+
+// runSyncEndpoints calls SyncEndpoints every interval with jitter until the client is closed
+func (c *Client) runSyncEndpoints(interval time.Duration) {
+	for {
+		// up to 20% jitter, so the clients do not query the cluster at the same time
+		delay := interval + time.Duration(rand.Int63n(int64(interval)/5+1))
+		timer := time.NewTimer(delay)
+		select {
+		case <-c.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := c.SyncEndpoints(); err != nil {
+			c.log.Warn("periodic sync endpoints failed", "error", err)
+		}
+	}
 }
 
 func (c *Client) formatURL(api string, querys []URLParameter, options *CallOptions) string {
-	host := c.GetAddress()
+	var host string
 	if options != nil && len(options.Address) != 0 {
 		host = options.Address
+	} else {
+		host = c.GetAddress()
 	}
 	builder := URLBuilder{
 		Protocol:      c.protocol,
@@ -269,13 +406,68 @@ func (c *Client) GetDefaultHeaders() http.Header {
 
 // httpDo makes the http request to Service-center with proper header, body and method
 func (c *Client) httpDo(method string, rawURL string, headers http.Header, body []byte) (resp *http.Response, err error) {
+	return c.httpDoContext(context.Background(), method, rawURL, headers, body)
+}
+
+// httpDoContext makes the http request which is canceled with the context
+func (c *Client) httpDoContext(ctx context.Context, method string, rawURL string, headers http.Header,
+	body []byte) (resp *http.Response, err error) {
 	if len(headers) == 0 {
 		headers = make(http.Header)
 	}
 	for k, v := range c.GetDefaultHeaders() {
 		headers[k] = v
 	}
-	return c.client.Do(context.Background(), method, rawURL, headers, body)
+	var class OperationClass
+	if c.limiter != nil {
+		class = operationClass(method, rawURL)
+		waitCtx := ctx
+		if waitCtx.Done() == nil {
+			// the requests without deadline still stop waiting when the client is closed
+			waitCtx = c.ctx
+		}
+		if err = c.limiter.wait(waitCtx, class); err != nil {
+			return nil, err
+		}
+	}
+	var host string
+	if u, parseErr := url.Parse(rawURL); parseErr == nil {
+		host = u.Host
+	}
+	if c.breakers != nil && !c.breakers.allow(host) {
+		return nil, &CircuitOpenError{Address: host}
+	}
+	start := time.Now()
+	resp, err = c.transport.Do(ctx, method, rawURL, headers, body)
+	latency := time.Since(start)
+	if ctx.Err() == nil {
+		// the canceled requests, for example the hedging losers, say nothing about the address
+		respErr := responseError(resp, err)
+		if c.breakers != nil {
+			c.breakers.done(host, respErr, latency)
+		}
+		c.endpoints.record(host, respErr, latency)
+		if respErr == nil {
+			c.endpoints.activate(host)
+		}
+	} else if c.breakers != nil {
+		c.breakers.release(host)
+	}
+	if c.limiter != nil {
+		c.limiter.observe(class, resp)
+	}
+	return resp, err
+}
+
+// responseError treats the server errors as the failures of the service center
+func responseError(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp != nil && resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("service center responds %d", resp.StatusCode)
+	}
+	return nil
 }
 
 // RegisterService registers the micro-services to Service-Center
@@ -471,19 +663,18 @@ func (c *Client) GetAllMicroServices(opts ...CallOption) ([]*discovery.MicroServ
 	if resp == nil {
 		return nil, fmt.Errorf("GetAllMicroServices failed, response is empty")
 	}
-	var body []byte
-	body, err = ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, NewIOException(err)
-	}
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
 		var response discovery.GetServicesResponse
-		err = json.Unmarshal(body, &response)
+		err = c.decodeBody(resp, &response)
 		if err != nil {
-			return nil, NewJSONException(err, string(body))
+			return nil, err
 		}
 		return response.Services, nil
 	}
+	body, err := c.readBody(resp)
+	if err != nil {
+		return nil, NewIOException(err)
+	}
 	return nil, fmt.Errorf("GetAllMicroServices failed, response StatusCode: %d, response body: %s", resp.StatusCode, string(body))
 }
 
@@ -548,7 +739,8 @@ func (c *Client) GetMicroService(microServiceID string, opts ...CallOption) (*di
 }
 
 // BatchFindInstances fetch instances based on service name, env, app and version
-// finally it return instances grouped by service name
+// finally it return instances grouped by service name.
+// concurrent identical queries share one request and the same response
 func (c *Client) BatchFindInstances(consumerID string, keys []*discovery.FindService, opts ...CallOption) (*discovery.BatchFindInstancesResponse, error) {
 	copts := &CallOptions{}
 	for _, opt := range opts {
@@ -557,9 +749,6 @@ func (c *Client) BatchFindInstances(consumerID string, keys []*discovery.FindSer
 	if len(keys) == 0 {
 		return nil, ErrEmptyCriteria
 	}
-	url := c.formatURL(MSAPIPath+BatchInstancePath, []URLParameter{
-		{"type": "query"},
-	}, copts)
 	r := &discovery.BatchFindInstancesRequest{
 		ConsumerServiceId: consumerID,
 		Services:          keys,
@@ -568,23 +757,41 @@ func (c *Client) BatchFindInstances(consumerID string, keys []*discovery.FindSer
 	if err != nil {
 		return nil, NewJSONException(err, string(rBody))
 	}
-	resp, err := c.httpDo("POST", url, http.Header{"X-ConsumerId": []string{consumerID}}, rBody)
+	querys := []URLParameter{
+		{"type": "query"},
+	}
+	key := flightKey("POST", MSAPIPath+BatchInstancePath, querys, copts, consumerID, rBody)
+	v, err, _ := c.flights.do(key, func() (interface{}, error) {
+		return c.batchFindInstances(consumerID, querys, rBody, copts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*discovery.BatchFindInstancesResponse), nil
+}
+
+func (c *Client) batchFindInstances(consumerID string, querys []URLParameter, rBody []byte,
+	copts *CallOptions) (*discovery.BatchFindInstancesResponse, error) {
+	resp, err := c.hedgedDo("POST", MSAPIPath+BatchInstancePath, querys, copts,
+		http.Header{"X-ConsumerId": []string{consumerID}}, rBody)
 	if err != nil {
 		return nil, err
 	}
 	if resp == nil {
 		return nil, fmt.Errorf("BatchFindInstances failed, response is empty")
 	}
-	body := httputil.ReadBody(resp)
 	if resp.StatusCode == http.StatusOK {
 		var response *discovery.BatchFindInstancesResponse
-		err = json.Unmarshal(body, &response)
+		err = c.decodeBody(resp, &response)
 		if err != nil {
-			return nil, NewJSONException(err, string(body))
+			return nil, err
 		}
-
 		return response, nil
 	}
+	body, err := c.readBody(resp)
+	if err != nil {
+		return nil, NewIOException(err)
+	}
 	return nil, fmt.Errorf("batch find failed, status %d, body %s", resp.StatusCode, body)
 }
 
@@ -600,10 +807,22 @@ func (c *Client) FindMicroServiceInstances(consumerID, appID, microServiceName,
 	return rst.Instances, nil
 }
 
-// FindInstances find microservice instance
+// FindInstances find microservice instance, all versions unless WithVersionRule is given,
+// concurrent identical queries share one request and the same result
 func (c *Client) FindInstances(consumerID, appID, microServiceName string,
 	opts ...CallOption) (*FindMicroServiceInstancesResult, error) {
-	return c.findInstances(consumerID, appID, microServiceName, "0%2B", opts...) // 0+, all version
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	rule := copts.VersionRule
+	if rule == "" {
+		rule = VersionRuleAll
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return c.findInstances(consumerID, appID, microServiceName, string(rule), opts...)
 }
 
 // FindInstances find microservice instance using consumerID, appID, name
@@ -613,13 +832,44 @@ func (c *Client) findInstances(consumerID, appID, microServiceName,
 	for _, opt := range opts {
 		opt(copts)
 	}
-	microserviceInstanceURL := c.formatURL(MSAPIPath+InstancePath, []URLParameter{
+	versionRule = normalizeVersionRule(versionRule)
+	querys := []URLParameter{
 		{"appId": appID},
 		{"serviceName": microServiceName},
 		{"version": versionRule},
-	}, copts)
+	}
+	key := flightKey("GET", MSAPIPath+InstancePath, querys, copts, consumerID, nil)
+	v, err, shared := c.flights.do(key, func() (interface{}, error) {
+		rst, err := c.doFindInstances(consumerID, appID, microServiceName, versionRule, querys, copts)
+		if err == nil && c.snapshot != nil {
+			c.snapshot.put(appID, microServiceName, versionRule, rst)
+		}
+		return rst, err
+	})
+	if err != nil {
+		if c.snapshot == nil || err == ErrNotModified || err == ErrMicroServiceNotExists {
+			return nil, err
+		}
+		stale, ok := c.snapshot.get(appID, microServiceName, versionRule)
+		if !ok {
+			return nil, err
+		}
+		c.log.Warn("service center is unavailable, serve stale instances", "appID", appID,
+			"serviceName", microServiceName, "updatedAt", stale.UpdatedAt, "error", err)
+		return stale, nil
+	}
+	rst := v.(*FindMicroServiceInstancesResult)
+	if shared {
+		copied := *rst
+		return &copied, nil
+	}
+	return rst, nil
+}
 
-	resp, err := c.httpDo("GET", microserviceInstanceURL, http.Header{"X-ConsumerId": []string{consumerID}}, nil)
+func (c *Client) doFindInstances(consumerID, appID, microServiceName, versionRule string,
+	querys []URLParameter, copts *CallOptions) (*FindMicroServiceInstancesResult, error) {
+	resp, err := c.hedgedDo("GET", MSAPIPath+InstancePath, querys, copts,
+		http.Header{"X-ConsumerId": []string{consumerID}}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -723,21 +973,23 @@ func (c *Client) GetMicroServiceInstances(consumerID, providerID string, opts ..
 		consumerID, providerID, resp.StatusCode, string(body))
 }
 
-// GetAllResources retruns all the list of services, instances, providers, consumers in the service-center
-func (c *Client) GetAllResources(resource string, opts ...CallOption) ([]*discovery.ServiceDetail, error) {
+// GetInstance queries one instance of the provider,
+// it returns ErrMicroServiceNotExists or ErrMicroServiceInstanceNotExists if the provider or the instance is not found,
+// consumerID is empty if the caller does not query as a consumer, service center records no dependency then
+func (c *Client) GetInstance(consumerID, providerID, instanceID string, opts ...CallOption) (*discovery.MicroServiceInstance, error) {
 	copts := &CallOptions{}
 	for _, opt := range opts {
 		opt(copts)
 	}
-	url := c.formatURL(GovernAPIPATH+MicroservicePath, []URLParameter{
-		{"options": resource},
-	}, copts)
-	resp, err := c.httpDo("GET", url, nil, nil)
+	url := c.formatURL(fmt.Sprintf("%s%s/%s%s/%s", MSAPIPath, MicroservicePath, providerID, InstancePath, instanceID), nil, copts)
+	resp, err := c.httpDo("GET", url, http.Header{
+		"X-ConsumerId": []string{consumerID},
+	}, nil)
 	if err != nil {
 		return nil, err
 	}
 	if resp == nil {
-		return nil, errors.New("GetAllResources failed, response is empty")
+		return nil, fmt.Errorf("GetInstance failed, response is empty, ProviderId/InstanceId: %s/%s", providerID, instanceID)
 	}
 	var body []byte
 	body, err = ioutil.ReadAll(resp.Body)
@@ -745,14 +997,96 @@ func (c *Client) GetAllResources(resource string, opts ...CallOption) ([]*discov
 		return nil, NewIOException(err)
 	}
 	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-		var response discovery.GetServicesInfoResponse
+		var response discovery.GetOneInstanceResponse
 		err = json.Unmarshal(body, &response)
 		if err != nil {
 			return nil, NewJSONException(err, string(body))
 		}
-		return response.AllServicesDetail, nil
+		return response.Instance, nil
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		if strings.Contains(string(body), "\"errorCode\":\"400012\"") {
+			return nil, ErrMicroServiceNotExists
+		}
+		if strings.Contains(string(body), "\"errorCode\":\"400017\"") {
+			return nil, ErrMicroServiceInstanceNotExists
+		}
+	}
+	return nil, fmt.Errorf("GetInstance failed, ProviderId/InstanceId: %s/%s, response StatusCode: %d, response body: %s",
+		providerID, instanceID, resp.StatusCode, string(body))
+}
+
+// InstanceExists returns true if the instance of the service is registered
+func (c *Client) InstanceExists(microServiceID, microServiceInstanceID string, opts ...CallOption) (bool, error) {
+	_, err := c.GetInstance("", microServiceID, microServiceInstanceID, opts...)
+	if err == ErrMicroServiceNotExists || err == ErrMicroServiceInstanceNotExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SchemaExists returns the summary of the schema,
+// it returns ErrMicroServiceNotExists or ErrSchemaNotExists if the service or the schema is not found
+func (c *Client) SchemaExists(microServiceID, schemaID string, opts ...CallOption) (string, error) {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	url := c.formatURL(MSAPIPath+ExistencePath, []URLParameter{
+		{"type": "schema"},
+		{"serviceId": microServiceID},
+		{"schemaId": schemaID},
+	}, copts)
+	resp, err := c.httpDo("GET", url, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp == nil {
+		return "", fmt.Errorf("SchemaExists failed, response is empty, MicroServiceId/SchemaId: %s/%s", microServiceID, schemaID)
+	}
+	var body []byte
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", NewIOException(err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var response discovery.GetExistenceResponse
+		err = json.Unmarshal(body, &response)
+		if err != nil {
+			return "", NewJSONException(err, string(body))
+		}
+		if response.Summary == "" {
+			// old service center only puts the summary in header
+			return resp.Header.Get("X-Schema-Summary"), nil
+		}
+		return response.Summary, nil
+	}
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotFound {
+		if strings.Contains(string(body), "\"errorCode\":\"400012\"") {
+			return "", ErrMicroServiceNotExists
+		}
+		if strings.Contains(string(body), "\"errorCode\":\"400016\"") {
+			return "", ErrSchemaNotExists
+		}
 	}
-	return nil, fmt.Errorf("GetAllResources failed, response StatusCode: %d, response body: %s", resp.StatusCode, string(body))
+	return "", fmt.Errorf("SchemaExists failed, MicroServiceId/SchemaId: %s/%s, response StatusCode: %d, response body: %s",
+		microServiceID, schemaID, resp.StatusCode, string(body))
+}
+
+// GetAllResources retruns all the list of services, instances, providers, consumers in the service-center
+//
+// Deprecated: resource is a free-form string, use GetServiceDetails with WithGovernOptions instead
+func (c *Client) GetAllResources(resource string, opts ...CallOption) ([]*discovery.ServiceDetail, error) {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	return c.getServiceDetails("GetAllResources", []URLParameter{
+		{"options": resource},
+	}, copts)
 }
 
 // Health returns the list of all the endpoints of SC with their status
@@ -805,45 +1139,63 @@ func (c *Client) Heartbeat(microServiceID, microServiceInstanceID string) (bool,
 
 // WSHeartbeat creates a web socket connection to service-center to send heartbeat.
 // It relies on the ping pong mechanism of websocket to ensure the heartbeat, which is maintained by goroutines.
-// After the connection is established, the communication fails and will be retried continuously. The retrial time increases exponentially.
+// After the connection is established, the communication fails and will be retried continuously
+// by the reconnect scheduler of the client, preferring another service center address.
 // The callback function is used to re-register the instance.
 func (c *Client) WSHeartbeat(microServiceID, microServiceInstanceID string, callback func()) error {
-	err := c.setupWSConnection(microServiceID, microServiceInstanceID)
+	err := c.setupWSConnection(microServiceID, microServiceInstanceID, c.GetAddress())
 	if err != nil {
 		return err
 	}
 	go func() {
-		resetConn := func() error {
-			return c.setupWSConnection(microServiceID, microServiceInstanceID)
-		}
 		for {
-			conn := c.conns[microServiceInstanceID]
+			conn := c.conns.get(ConnKindHeartbeat, microServiceInstanceID)
+			if conn == nil {
+				c.log.Info("heartbeat connection closed", "serviceID", microServiceID, "instanceID", microServiceInstanceID)
+				return
+			}
 			_, _, err = conn.ReadMessage()
 			if err != nil {
-				openlog.Error(err.Error())
-				closeErr := conn.Close()
+				c.log.Warn("heartbeat connection broken", "serviceID", microServiceID,
+					"instanceID", microServiceInstanceID, "error", err)
+				closeErr := c.conns.backoff(ConnKindHeartbeat, microServiceInstanceID, err)
+				if closeErr == ErrConnNotExists {
+					return
+				}
 				if closeErr != nil {
-					openlog.Error(fmt.Sprintf("failed to close websocket connection %s", closeErr.Error()))
+					c.log.Error("failed to close websocket connection", "instanceID", microServiceInstanceID, "error", closeErr)
 				}
 				if websocket.IsCloseError(err, discovery.ErrWebsocketInstanceNotExists) {
 					// If the instance does not exist, it is closed normally and should be re-registered
 					callback()
 				}
 				// reconnection
-				err = backoff.RetryNotify(
-					resetConn,
-					backoff.NewExponentialBackOff(),
-					func(err error, duration time.Duration) {
-						openlog.Error(fmt.Sprintf("failed err: %s,and it will be executed again in %v", err.Error(), duration))
-					})
+				failed := map[string]bool{c.conns.endpoint(ConnKindHeartbeat, microServiceInstanceID): true}
+				err = c.reconnector.Retry(c.ctx, func(attempt int) error {
+					host := c.reconnectAddress(failed)
+					err := c.setupWSConnection(microServiceID, microServiceInstanceID, host)
+					if err != nil {
+						failed[host] = true
+					}
+					return err
+				}, func(err error, next time.Duration) {
+					c.log.Info("heartbeat reconnect failed", "serviceID", microServiceID,
+						"instanceID", microServiceInstanceID, "retryIn", next, "error", err)
+				})
+				if err != nil {
+					c.log.Error("give up reconnecting heartbeat", "serviceID", microServiceID,
+						"instanceID", microServiceInstanceID, "error", err)
+					_ = c.conns.close(ConnKindHeartbeat, microServiceInstanceID)
+					return
+				}
 			}
 		}
 	}()
 	return nil
 }
 
-// setupWSConnection create websocket connection and assign it to the map of the connection
-func (c *Client) setupWSConnection(microServiceID, microServiceInstanceID string) error {
+// setupWSConnection create websocket connection and hand it over to the connection manager
+func (c *Client) setupWSConnection(microServiceID, microServiceInstanceID, host string) error {
 	scheme := "wss"
 	if !c.opt.EnableSSL {
 		scheme = "ws"
@@ -851,18 +1203,19 @@ func (c *Client) setupWSConnection(microServiceID, microServiceInstanceID string
 
 	u := url.URL{
 		Scheme: scheme,
-		Host:   c.GetAddress(),
+		Host:   host,
 		Path: fmt.Sprintf("%s%s/%s%s/%s%s", MSAPIPath, MicroservicePath, microServiceID,
 			InstancePath, microServiceInstanceID, "/heartbeat"),
 	}
 
-	conn, _, err := c.dialWebsocket(&u)
+	_, err := c.conns.open(ConnKindHeartbeat, microServiceInstanceID, &u)
 	if err != nil {
-		openlog.Error(fmt.Sprintf("watching microservice dial catch an exception,microServiceID: %s, error:%s", microServiceID, err.Error()))
+		c.log.Error("heartbeat dial failed", "serviceID", microServiceID,
+			"instanceID", microServiceInstanceID, "endpoint", u.Host, "error", err)
 		return err
 	}
-	c.conns[microServiceInstanceID] = conn
-	openlog.Info(fmt.Sprintf("%s's websocket connection established successfully", microServiceInstanceID))
+	c.log.Info("heartbeat connection established", "serviceID", microServiceID,
+		"instanceID", microServiceInstanceID, "endpoint", u.Host)
 	return nil
 }
 
@@ -887,7 +1240,8 @@ func (c *Client) UnregisterMicroServiceInstance(microServiceID, microServiceInst
 	return true, nil
 }
 
-// UnregisterMicroService un-registers the microservice from the service-center
+// UnregisterMicroService un-registers the microservice from the service-center,
+// it is forced even if the service has instances, use DeleteService to delete it without force
 func (c *Client) UnregisterMicroService(microServiceID string) (bool, error) {
 	url := c.formatURL(fmt.Sprintf("%s%s/%s", MSAPIPath, MicroservicePath, microServiceID), []URLParameter{
 		{"force": "1"},
@@ -999,47 +1353,108 @@ func (c *Client) UpdateMicroServiceProperties(microServiceID string, microServic
 	return true, nil
 }
 
+// AddServiceTags adds the tags to the micro service, existing tags with the same keys are updated
+func (c *Client) AddServiceTags(microServiceID string, tags map[string]string) error {
+	if microServiceID == "" {
+		return errors.New("invalid micro service ID")
+	}
+	request := &discovery.AddServiceTagsRequest{
+		ServiceId: microServiceID,
+		Tags:      tags,
+	}
+	url := c.formatURL(fmt.Sprintf("%s%s/%s%s", MSAPIPath, MicroservicePath, microServiceID, TagsPath), nil, nil)
+	body, err := json.Marshal(request)
+	if err != nil {
+		return NewJSONException(err, string(body))
+	}
+	resp, err := c.httpDo("POST", url, nil, body)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return fmt.Errorf("AddServiceTags failed, response is empty, MicroServiceId: %s", microServiceID)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewCommonException("result: %d %s", resp.StatusCode, string(httputil.ReadBody(resp)))
+	}
+	return nil
+}
+
+// AddDependencies adds the providers of the consumers, the existing dependencies are kept
+func (c *Client) AddDependencies(dependencies []*discovery.ConsumerDependency) error {
+	if len(dependencies) == 0 {
+		return ErrNil
+	}
+	request := &discovery.AddDependenciesRequest{
+		Dependencies: dependencies,
+	}
+	url := c.formatURL(MSAPIPath+DependencyPath, nil, nil)
+	body, err := json.Marshal(request)
+	if err != nil {
+		return NewJSONException(err, string(body))
+	}
+	resp, err := c.httpDo("POST", url, nil, body)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return fmt.Errorf("AddDependencies failed, response is empty")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return NewCommonException("result: %d %s", resp.StatusCode, string(httputil.ReadBody(resp)))
+	}
+	return nil
+}
+
 // Close closes the connection with Service-Center
 func (c *Client) Close() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	for k, v := range c.conns {
-		err := v.Close()
-		if err != nil {
-			return fmt.Errorf("error:%s, microServiceID = %s", err.Error(), k)
+	c.cancel()
+	err := c.conns.closeAll()
+	c.pool.Close()
+	if c.snapshot != nil {
+		if flushErr := c.snapshot.flush(); flushErr != nil && err == nil {
+			err = flushErr
 		}
-		delete(c.conns, k)
 	}
-	c.pool.Close()
-	return nil
+	return err
+}
+
+// Connections returns the snapshot of all websocket connections for diagnostics
+func (c *Client) Connections() []ConnInfo {
+	return c.conns.snapshot()
 }
 
 func (c *Client) WatchMicroServiceWithExtraHandle(microServiceID string, callback func(e *MicroServiceInstanceChangedEvent),
 	extraHandle func(action string, opts ...CallOption)) error {
-	openlog.Info(fmt.Sprintf("WatchMicroServiceWithExtraHandle, microServiceID:%s", microServiceID))
+	return c.watchMicroServiceWithExtraHandle(microServiceID, callback, extraHandle, c.GetAddress())
+}
+
+func (c *Client) watchMicroServiceWithExtraHandle(microServiceID string, callback func(e *MicroServiceInstanceChangedEvent),
+	extraHandle func(action string, opts ...CallOption), host string) error {
+	c.log.Debug("watch microservice", "serviceID", microServiceID)
 	c.mutex.Lock()
 	if ready, ok := c.watchers[microServiceID]; !ok || !ready {
-		openlog.Info(fmt.Sprintf("WatchMicroServiceWithExtraHandle watch, microServiceID:%s", microServiceID))
+		c.log.Info("start watching microservice", "serviceID", microServiceID)
 		c.watchers[microServiceID] = true
 		scheme := "wss"
 		if !c.opt.EnableSSL {
 			scheme = "ws"
 		}
-		host := c.GetAddress()
 		u := url.URL{
 			Scheme: scheme,
 			Host:   host,
 			Path: fmt.Sprintf("%s%s/%s%s", MSAPIPath,
 				MicroservicePath, microServiceID, WatchPath),
 		}
-		conn, _, err := c.dialWebsocket(&u)
+		conn, err := c.conns.open(ConnKindWatch, microServiceID, &u)
 		if err != nil {
 			c.watchers[microServiceID] = false
 			c.mutex.Unlock()
 			return fmt.Errorf("watching microservice dial catch an exception,microServiceID: %s, error:%s", microServiceID, err.Error())
 		}
 
-		c.conns[microServiceID] = conn
 		// After successfully subscribing to the service, pull the dependency again.
 		// This prevents the event from not being notified after one of the dual engines fails and the other has no dependencies.
 		extraHandle("watchSucceed", WithAddress(host))
@@ -1047,7 +1462,7 @@ func (c *Client) WatchMicroServiceWithExtraHandle(microServiceID string, callbac
 			for {
 				messageType, message, err := conn.ReadMessage()
 				if err != nil {
-					openlog.Error(fmt.Sprintf("%s:%s", "conn.ReadMessage()", err.Error()))
+					c.log.Warn("watch connection broken", "serviceID", microServiceID, "endpoint", host, "error", err)
 					break
 				}
 				if messageType == websocket.TextMessage {
@@ -1055,35 +1470,34 @@ func (c *Client) WatchMicroServiceWithExtraHandle(microServiceID string, callbac
 					err := json.Unmarshal(message, &response)
 					if err != nil {
 						if strings.Contains(string(message), "service does not exist") {
-							openlog.Error(fmt.Sprintf("%s:%s", "json.Unmarshal(message, &response), message", string(message)))
+							c.log.Error("watched service does not exist", "serviceID", microServiceID, "message", string(message))
 							c.mutex.Lock()
-							delete(c.conns, microServiceID)
+							_ = c.conns.close(ConnKindWatch, microServiceID)
 							delete(c.watchers, microServiceID)
 							c.mutex.Unlock()
-							openlog.Info(fmt.Sprintf("delete conn, microServiceID:%s", microServiceID))
+							c.log.Info("watch connection deleted", "serviceID", microServiceID)
 							extraHandle("serviceNotExist")
 							return
 						}
-						openlog.Error(fmt.Sprintf("%s:%s", "json.Unmarshal(message, &response), message", string(message)))
-						openlog.Error(fmt.Sprintf("%s:%s", "json.Unmarshal(message, &response)", err.Error()))
+						c.log.Error("unmarshal watch event failed", "serviceID", microServiceID,
+							"message", string(message), "error", err)
 						break
 					}
 					callback(&response)
 				}
 			}
 			if c.needCancelWatching(microServiceID) {
-				openlog.Info(fmt.Sprintf("microServiceID:%s Cancel Watching", microServiceID))
+				c.log.Info("watching canceled", "serviceID", microServiceID)
 				return
 			}
-			err = conn.Close()
+			c.mutex.Lock()
+			err = c.conns.backoff(ConnKindWatch, microServiceID, nil)
 			if err != nil {
-				openlog.Error(fmt.Sprintf("%s:%s", "conn.Close()", err.Error()))
+				c.log.Error("close watch connection failed", "serviceID", microServiceID, "error", err)
 			}
-			c.mutex.Lock()
-			delete(c.conns, microServiceID)
 			delete(c.watchers, microServiceID)
 			c.mutex.Unlock()
-			openlog.Info(fmt.Sprintf("conn stop, microServiceID:%s", microServiceID))
+			c.log.Info("watch connection stopped, reconnecting", "serviceID", microServiceID)
 			c.startBackOffWithExtraHandle(microServiceID, callback, extraHandle)
 		}()
 	}
@@ -1094,7 +1508,7 @@ func (c *Client) WatchMicroServiceWithExtraHandle(microServiceID string, callbac
 func (c *Client) needCancelWatching(microServiceID string) bool {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	_, connExist := c.conns[microServiceID]
+	connExist := c.conns.exists(ConnKindWatch, microServiceID)
 	_, watcherExist := c.watchers[microServiceID]
 	if connExist || watcherExist {
 		return false
@@ -1105,52 +1519,49 @@ func (c *Client) needCancelWatching(microServiceID string) bool {
 func (c *Client) DisconnectMicroServiceWatching(microServiceID string) {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	conn, ok := c.conns[microServiceID]
-	if !ok {
-		openlog.Info(fmt.Sprintf("conn not exist, microServiceID:%s", microServiceID))
+	err := c.conns.close(ConnKindWatch, microServiceID)
+	if err == ErrConnNotExists {
+		c.log.Info("watch connection does not exist", "serviceID", microServiceID)
 		delete(c.watchers, microServiceID)
 		return
 	}
-	err := conn.Close()
 	if err != nil {
-		openlog.Error(fmt.Sprintf("microServiceID:%s conn.Close() error:%s", microServiceID, err.Error()))
+		c.log.Error("close watch connection failed", "serviceID", microServiceID, "error", err)
 	}
-	delete(c.conns, microServiceID)
 	delete(c.watchers, microServiceID)
 }
 
 func (c *Client) startBackOffWithExtraHandle(microServiceID string, callback func(*MicroServiceInstanceChangedEvent),
 	extraHandle func(action string, opts ...CallOption)) {
-	boff := &backoff.ExponentialBackOff{
-		InitialInterval:     1000 * time.Millisecond,
-		RandomizationFactor: backoff.DefaultRandomizationFactor,
-		Multiplier:          backoff.DefaultMultiplier,
-		MaxInterval:         30000 * time.Millisecond,
-		MaxElapsedTime:      0,
-		Clock:               backoff.SystemClock,
-	}
-	operation := func() error {
+	failed := map[string]bool{c.conns.endpoint(ConnKindWatch, microServiceID): true}
+	operation := func(attempt int) error {
 		c.mutex.Lock()
 		c.watchers[microServiceID] = false
-		c.GetAddress()
 		c.mutex.Unlock()
-		err := c.WatchMicroServiceWithExtraHandle(microServiceID, callback, extraHandle)
+		host := c.reconnectAddress(failed)
+		err := c.watchMicroServiceWithExtraHandle(microServiceID, callback, extraHandle, host)
 		if err != nil {
-			openlog.Error(fmt.Sprintf("%s:%s", "startBackOffWithExtraHandle:WatchMicroServiceWithExtraHandle error", err.Error()))
+			failed[host] = true
+			c.log.Info("rewatch microservice failed", "serviceID", microServiceID,
+				"endpoint", host, "attempt", attempt, "error", err)
 			return err
 		}
 		return nil
 	}
 
-	err := backoff.Retry(operation, boff)
+	err := c.reconnector.Retry(c.ctx, operation, nil)
 	if err == nil {
 		return
 	}
-	openlog.Error(fmt.Sprintf("%s:%s", "backoff.Retry", err.Error()))
+	c.log.Error("give up rewatching microservice", "serviceID", microServiceID, "error", err)
 }
 
 // WatchMicroService creates a web socket connection to service-center to keep a watch on the providers for a micro-service
 func (c *Client) WatchMicroService(microServiceID string, callback func(*MicroServiceInstanceChangedEvent)) error {
+	return c.watchMicroService(microServiceID, callback, c.GetAddress())
+}
+
+func (c *Client) watchMicroService(microServiceID string, callback func(*MicroServiceInstanceChangedEvent), host string) error {
 	if ready, ok := c.watchers[microServiceID]; !ok || !ready {
 		c.mutex.Lock()
 		if ready, ok := c.watchers[microServiceID]; !ok || !ready {
@@ -1161,18 +1572,17 @@ func (c *Client) WatchMicroService(microServiceID string, callback func(*MicroSe
 			}
 			u := url.URL{
 				Scheme: scheme,
-				Host:   c.GetAddress(),
+				Host:   host,
 				Path: fmt.Sprintf("%s%s/%s%s", MSAPIPath,
 					MicroservicePath, microServiceID, WatchPath),
 			}
-			conn, _, err := c.dialWebsocket(&u)
+			conn, err := c.conns.open(ConnKindWatch, microServiceID, &u)
 			if err != nil {
 				c.watchers[microServiceID] = false
 				c.mutex.Unlock()
 				return fmt.Errorf("watching microservice dial catch an exception,microServiceID: %s, error:%s", microServiceID, err.Error())
 			}
 
-			c.conns[microServiceID] = conn
 			go func() {
 				for {
 					messageType, message, err := conn.ReadMessage()
@@ -1188,13 +1598,12 @@ func (c *Client) WatchMicroService(microServiceID string, callback func(*MicroSe
 						callback(&response)
 					}
 				}
-				err = conn.Close()
-				if err != nil {
-					openlog.Error(err.Error())
-				}
 				c.mutex.Lock()
-				delete(c.conns, microServiceID)
+				err = c.conns.backoff(ConnKindWatch, microServiceID, nil)
 				c.mutex.Unlock()
+				if err != nil {
+					c.log.Error("close watch connection failed", "serviceID", microServiceID, "error", err)
+				}
 				c.startBackOff(microServiceID, callback)
 			}()
 		}
@@ -1204,34 +1613,56 @@ func (c *Client) WatchMicroService(microServiceID string, callback func(*MicroSe
 }
 
 func (c *Client) GetAddress() string {
-	return c.pool.GetAvailableAddress()
+	return c.chooseAddress()
+}
+
+func (c *Client) chooseAddress() string {
+	addr := c.pool.GetAvailableAddress()
+	if c.breakers == nil || c.breakers.available(addr) {
+		return addr
+	}
+	for _, candidate := range c.candidateAddresses() {
+		if candidate != addr && c.breakers.available(candidate) {
+			return candidate
+		}
+	}
+	return addr
+}
+
+// candidateAddresses returns the configured and the synced service center addresses, same AZ first
+func (c *Client) candidateAddresses() []string {
+	return mergeAddresses(c.sameAZAddresses(), c.opt.DiffAzEndpoints)
+}
+
+// sameAZAddresses returns the configured or resolved addresses and the synced members
+func (c *Client) sameAZAddresses() []string {
+	c.membersMutex.RLock()
+	defer c.membersMutex.RUnlock()
+	return mergeAddresses(c.static, c.members)
 }
 
 func (c *Client) startBackOff(microServiceID string, callback func(*MicroServiceInstanceChangedEvent)) {
-	boff := &backoff.ExponentialBackOff{
-		InitialInterval:     1000 * time.Millisecond,
-		RandomizationFactor: backoff.DefaultRandomizationFactor,
-		Multiplier:          backoff.DefaultMultiplier,
-		MaxInterval:         30000 * time.Millisecond,
-		MaxElapsedTime:      0,
-		Clock:               backoff.SystemClock,
-	}
-	operation := func() error {
+	failed := map[string]bool{c.conns.endpoint(ConnKindWatch, microServiceID): true}
+	operation := func(attempt int) error {
 		c.mutex.Lock()
 		c.watchers[microServiceID] = false
-		c.GetAddress()
 		c.mutex.Unlock()
-		err := c.WatchMicroService(microServiceID, callback)
+		host := c.reconnectAddress(failed)
+		err := c.watchMicroService(microServiceID, callback, host)
 		if err != nil {
+			failed[host] = true
+			c.log.Info("rewatch microservice failed", "serviceID", microServiceID,
+				"endpoint", host, "attempt", attempt, "error", err)
 			return err
 		}
 		return nil
 	}
 
-	err := backoff.Retry(operation, boff)
+	err := c.reconnector.Retry(c.ctx, operation, nil)
 	if err == nil {
 		return
 	}
+	c.log.Error("give up rewatching microservice", "serviceID", microServiceID, "error", err)
 }
 
 // GetToken generate token according to user-password
@@ -1265,6 +1696,7 @@ func (c *Client) GetTokenWithExpiration(a *rbac.AuthUser, expiration string) (st
 	}
 
 	if resp.StatusCode == http.StatusOK {
+		c.log.Debug("token generated", "user", a.Username)
 		var response rbac.Token
 		err = json.Unmarshal(body, &response)
 		if err != nil {
@@ -1272,6 +1704,7 @@ func (c *Client) GetTokenWithExpiration(a *rbac.AuthUser, expiration string) (st
 		}
 		return response.TokenStr, nil
 	}
+	c.log.Warn("generate token failed", "user", a.Username, "status", resp.StatusCode)
 	return "", fmt.Errorf("user %s generate token failed, response status code: %d", a.Username, resp.StatusCode)
 }
 
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/go-chassis/cari/rbac"
+
+	"github.com/go-chassis/sc-client"
+)
+
+// stringList is a flag which can be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runApps(g *globalOptions, args []string) error {
+	if _, err := parseFlags(g, "apps", flag.NewFlagSet("apps", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	apps, err := c.GetAllApplications(g.callOptions()...)
+	if err != nil {
+		return err
+	}
+	sort.Strings(apps)
+	rows := make([][]string, 0, len(apps))
+	for _, app := range apps {
+		rows = append(rows, []string{app})
+	}
+	return g.print(apps, []string{"APP"}, rows)
+}
+
+func runServices(g *globalOptions, args []string) error {
+	fs := flag.NewFlagSet("services", flag.ContinueOnError)
+	app := fs.String("app", "", "only list the services of the app")
+	if _, err := parseFlags(g, "services", fs, args, 0, 0); err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	services, err := c.GetAllMicroServices(g.callOptions()...)
+	if err != nil {
+		return err
+	}
+	filtered := make([]*discovery.MicroService, 0, len(services))
+	for _, s := range services {
+		if *app == "" || s.AppId == *app {
+			filtered = append(filtered, s)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].AppId != filtered[j].AppId {
+			return filtered[i].AppId < filtered[j].AppId
+		}
+		if filtered[i].ServiceName != filtered[j].ServiceName {
+			return filtered[i].ServiceName < filtered[j].ServiceName
+		}
+		return filtered[i].Version < filtered[j].Version
+	})
+	rows := make([][]string, 0, len(filtered))
+	for _, s := range filtered {
+		rows = append(rows, []string{s.ServiceId, s.Environment, s.AppId, s.ServiceName, s.Version, s.Status})
+	}
+	return g.print(filtered, []string{"ID", "ENV", "APP", "NAME", "VERSION", "STATUS"}, rows)
+}
+
+func runInstances(g *globalOptions, args []string) error {
+	fs := flag.NewFlagSet("instances", flag.ContinueOnError)
+	versionRule := fs.String("version-rule", string(sc.VersionRuleAll), "versions of the service: latest, 1.0.0, 1.0.0-2.0.0 or 1.0.0+")
+	args, err := parseFlags(g, "instances", fs, args, 2, 2)
+	if err != nil {
+		return err
+	}
+	rule, err := sc.ParseVersionRule(*versionRule)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	rst, err := c.FindInstances("", args[0], args[1], append(g.callOptions(), sc.WithoutRevision(), sc.WithVersionRule(rule))...)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(rst.Instances))
+	for _, i := range rst.Instances {
+		rows = append(rows, []string{i.InstanceId, i.ServiceId, i.HostName, i.Status, strings.Join(i.Endpoints, ",")})
+	}
+	return g.print(rst.Instances, []string{"ID", "SERVICE", "HOST", "STATUS", "ENDPOINTS"}, rows)
+}
+
+func runSchemas(g *globalOptions, args []string) error {
+	args, err := parseFlags(g, "schemas", flag.NewFlagSet("schemas", flag.ContinueOnError), args, 1, 2)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if len(args) == 2 {
+		schema, err := c.GetSchema(args[0], args[1], g.callOptions()...)
+		if err != nil {
+			return err
+		}
+		// the schema is printed as it is whatever the output format is
+		_, err = fmt.Fprintln(g.stdout, string(schema))
+		return err
+	}
+	service, err := c.GetMicroService(args[0], g.callOptions()...)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(service.Schemas))
+	for _, id := range service.Schemas {
+		rows = append(rows, []string{id})
+	}
+	return g.print(service.Schemas, []string{"SCHEMA"}, rows)
+}
+
+func runRegisterService(g *globalOptions, args []string) error {
+	fs := flag.NewFlagSet("register-service", flag.ContinueOnError)
+	service := &discovery.MicroService{}
+	fs.StringVar(&service.AppId, "app", "default", "app of the service")
+	fs.StringVar(&service.ServiceName, "name", "", "name of the service")
+	fs.StringVar(&service.Version, "version", "", "version of the service")
+	fs.StringVar(&service.Environment, "env", "", "environment of the service")
+	if _, err := parseFlags(g, "register-service", fs, args, 0, 0); err != nil {
+		return err
+	}
+	if service.ServiceName == "" || service.Version == "" {
+		fs.Usage()
+		return errUsage
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	id, err := c.RegisterService(service)
+	if err != nil {
+		return err
+	}
+	return g.print(map[string]string{"serviceId": id}, []string{"ID"}, [][]string{{id}})
+}
+
+func runDeregisterService(g *globalOptions, args []string) error {
+	args, err := parseFlags(g, "deregister-service", flag.NewFlagSet("deregister-service", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if _, err = c.UnregisterMicroService(args[0]); err != nil {
+		return err
+	}
+	return printDone(g, "service %s deregistered", args[0])
+}
+
+func runRegisterInstance(g *globalOptions, args []string) error {
+	fs := flag.NewFlagSet("register-instance", flag.ContinueOnError)
+	instance := &discovery.MicroServiceInstance{}
+	var endpoints stringList
+	fs.StringVar(&instance.ServiceId, "service", "", "id of the service")
+	fs.StringVar(&instance.HostName, "host", "", "host name of the instance")
+	fs.StringVar(&instance.Status, "status", sc.MSInstanceUP, "status of the instance")
+	fs.Var(&endpoints, "endpoint", "endpoint of the instance, for example rest://127.0.0.1:8080, can be repeated")
+	if _, err := parseFlags(g, "register-instance", fs, args, 0, 0); err != nil {
+		return err
+	}
+	if instance.ServiceId == "" || instance.HostName == "" || len(endpoints) == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	instance.Endpoints = endpoints
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	id, err := c.RegisterMicroServiceInstance(instance)
+	if err != nil {
+		return err
+	}
+	return g.print(map[string]string{"instanceId": id}, []string{"ID"}, [][]string{{id}})
+}
+
+func runDeregisterInstance(g *globalOptions, args []string) error {
+	args, err := parseFlags(g, "deregister-instance", flag.NewFlagSet("deregister-instance", flag.ContinueOnError), args, 2, 2)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if _, err = c.UnregisterMicroServiceInstance(args[0], args[1]); err != nil {
+		return err
+	}
+	return printDone(g, "instance %s deregistered", args[1])
+}
+
+func runStatus(g *globalOptions, args []string) error {
+	args, err := parseFlags(g, "status", flag.NewFlagSet("status", flag.ContinueOnError), args, 3, 3)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	status := strings.ToUpper(args[2])
+	if _, err = c.UpdateMicroServiceInstanceStatus(args[0], args[1], status); err != nil {
+		return err
+	}
+	return printDone(g, "instance %s is %s", args[1], status)
+}
+
+func runToken(g *globalOptions, args []string) error {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	expiration := fs.String("expiration", "", "expiration of the token, 15m~24h, default is 12h")
+	if _, err := parseFlags(g, "token", fs, args, 0, 0); err != nil {
+		return err
+	}
+	if g.username == "" {
+		return fmt.Errorf("-username is required")
+	}
+	user := &rbac.AuthUser{Username: g.username, Password: g.password}
+	// the token is fetched with the user name and password, do not authenticate the client itself
+	g.username = ""
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	token, err := c.GetTokenWithExpiration(user, *expiration)
+	if err != nil {
+		return err
+	}
+	return g.print(map[string]string{"token": token}, []string{"TOKEN"}, [][]string{{token}})
+}
+
+func runPeers(g *globalOptions, args []string) error {
+	if _, err := parseFlags(g, "peers", flag.NewFlagSet("peers", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	status, err := c.CheckPeerStatus()
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(status.Peers))
+	for _, p := range status.Peers {
+		rows = append(rows, []string{p.Name, p.Kind, strings.Join(p.Mode, ","), p.PeerStatus().String(), strings.Join(p.Endpoints, ",")})
+	}
+	return g.print(status, []string{"NAME", "KIND", "MODE", "STATUS", "ENDPOINTS"}, rows)
+}
+
+func runWatch(g *globalOptions, args []string) error {
+	args, err := parseFlags(g, "watch", flag.NewFlagSet("watch", flag.ContinueOnError), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	c, err := g.client()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	p, err := newPrinter(g.output, g.stdout)
+	if err != nil {
+		return err
+	}
+	events := make(chan *sc.MicroServiceInstanceChangedEvent, 16)
+	err = c.WatchMicroService(args[0], func(e *sc.MicroServiceInstanceChangedEvent) {
+		events <- e
+	})
+	if err != nil {
+		return err
+	}
+	header := []string{"ACTION", "APP", "SERVICE", "VERSION", "INSTANCE", "STATUS", "ENDPOINTS"}
+	if err = p.printHeader(header); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-g.interruptions:
+			return nil
+		case e := <-events:
+			row := make([]string, len(header))
+			row[0] = e.Action
+			if e.Key != nil {
+				row[1], row[2], row[3] = e.Key.AppId, e.Key.ServiceName, e.Key.Version
+			}
+			if e.Instance != nil {
+				row[4], row[5], row[6] = e.Instance.InstanceId, e.Instance.Status, strings.Join(e.Instance.Endpoints, ",")
+			}
+			if err = p.printEvent(e, row); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func printDone(g *globalOptions, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	return g.print(map[string]string{"result": msg}, []string{"RESULT"}, [][]string{{msg}})
+}
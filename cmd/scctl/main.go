@@ -0,0 +1,242 @@
+// scctl is the command line tool of service center built on the sc client
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chassis/cari/rbac"
+
+	"github.com/go-chassis/sc-client"
+)
+
+// errUsage means the arguments are wrong, the usage is already printed
+var errUsage = errors.New("invalid usage")
+
+// globalOptions mirrors sc.Options
+type globalOptions struct {
+	endpoints     string
+	project       string
+	timeout       time.Duration
+	output        string
+	tls           bool
+	caFile        string
+	certFile      string
+	keyFile       string
+	skipVerify    bool
+	username      string
+	password      string
+	token         string
+	verbose       bool
+	global        bool
+	stdout        io.Writer
+	stderr        io.Writer
+	newClient     func(sc.Options) (*sc.Client, error)
+	interruptions <-chan os.Signal
+}
+
+type command struct {
+	usage string
+	short string
+	run   func(g *globalOptions, args []string) error
+}
+
+var commands map[string]*command
+
+// commands refer to each other through the usage, so they are registered in init
+func init() {
+	commands = map[string]*command{
+		"apps":                {"apps", "list the applications", runApps},
+		"services":            {"services", "list the micro services", runServices},
+		"instances":           {"instances [-version-rule rule] <app> <service>", "list the instances of a micro service", runInstances},
+		"schemas":             {"schemas <serviceID> [schemaID]", "list the schema ids of a micro service, or show a schema", runSchemas},
+		"register-service":    {"register-service -app <app> -name <name> -version <version> [-env env]", "register a micro service", runRegisterService},
+		"deregister-service":  {"deregister-service <serviceID>", "deregister a micro service", runDeregisterService},
+		"register-instance":   {"register-instance -service <serviceID> -host <host> -endpoint <endpoint>...", "register an instance", runRegisterInstance},
+		"deregister-instance": {"deregister-instance <serviceID> <instanceID>", "deregister an instance", runDeregisterInstance},
+		"status":              {"status <serviceID> <instanceID> <UP|DOWN|STARTING|OUTOFSERVICE|TESTING>", "update the status of an instance", runStatus},
+		"token":               {"token [-expiration 12h]", "fetch a token with -username and -password", runToken},
+		"peers":               {"peers", "check the status of the syncer peers", runPeers},
+		"watch":               {"watch <serviceID>", "stream the instance events of the providers of a micro service", runWatch},
+	}
+}
+
+func main() {
+	g := &globalOptions{
+		stdout:        os.Stdout,
+		stderr:        os.Stderr,
+		newClient:     sc.NewClient,
+		interruptions: notifyInterrupt(),
+	}
+	if err := run(g, os.Args[1:]); err != nil {
+		if err != errUsage {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+		os.Exit(1)
+	}
+}
+
+func run(g *globalOptions, args []string) error {
+	fs := flag.NewFlagSet("scctl", flag.ContinueOnError)
+	fs.SetOutput(g.stderr)
+	fs.StringVar(&g.endpoints, "endpoints", envOr("SC_ENDPOINTS", "127.0.0.1:30100"), "comma separated addresses of service center, env SC_ENDPOINTS")
+	fs.StringVar(&g.project, "project", "", "project of the resources, env CSE_PROJECT_ID")
+	fs.DurationVar(&g.timeout, "timeout", 10*time.Second, "timeout of each request")
+	fs.StringVar(&g.output, "o", "table", "output format: table, json or yaml")
+	fs.BoolVar(&g.tls, "tls", false, "connect with https")
+	fs.StringVar(&g.caFile, "ca", "", "CA file to verify service center")
+	fs.StringVar(&g.certFile, "cert", "", "client certificate file")
+	fs.StringVar(&g.keyFile, "key", "", "client key file")
+	fs.BoolVar(&g.skipVerify, "insecure-skip-verify", false, "do not verify the certificate of service center")
+	fs.StringVar(&g.username, "username", os.Getenv("SC_USERNAME"), "user name for the authentication, env SC_USERNAME")
+	fs.StringVar(&g.password, "password", os.Getenv("SC_PASSWORD"), "password for the authentication, env SC_PASSWORD")
+	fs.StringVar(&g.token, "token", os.Getenv("SC_TOKEN"), "token for the authentication, env SC_TOKEN")
+	fs.BoolVar(&g.verbose, "v", false, "print the requests")
+	fs.BoolVar(&g.global, "global", false, "include the resources of the aggregated service centers")
+	fs.Usage = func() {
+		fmt.Fprintln(g.stderr, "Usage: scctl [global flags] <command> [flags] [args]")
+		fmt.Fprintln(g.stderr, "\nCommands:")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(g.stderr, "  %-20s %s\n", name, commands[name].short)
+		}
+		fmt.Fprintln(g.stderr, "\nGlobal flags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(g.stderr, "unknown command %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+	if _, err := newPrinter(g.output, g.stdout); err != nil {
+		return err
+	}
+	if g.project != "" {
+		// the API paths of the client are built from the env
+		os.Setenv(sc.EnvProjectID, g.project)
+	}
+	return cmd.run(g, fs.Args()[1:])
+}
+
+// options converts the global flags to sc.Options
+func (g *globalOptions) options() (sc.Options, error) {
+	opt := sc.Options{
+		Endpoints: splitList(g.endpoints),
+		Timeout:   g.timeout,
+		Verbose:   g.verbose,
+		AuthToken: g.token,
+		LogLevel:  sc.LevelSilent,
+	}
+	if g.verbose {
+		opt.LogLevel = sc.LevelDebug
+	}
+	if g.username != "" {
+		opt.EnableAuth = true
+		opt.AuthUser = &rbac.AuthUser{Username: g.username, Password: g.password}
+	}
+	if !g.tls && g.caFile == "" && g.certFile == "" && !g.skipVerify {
+		return opt, nil
+	}
+	opt.EnableSSL = true
+	opt.TLSConfig = &tls.Config{InsecureSkipVerify: g.skipVerify} // #nosec G402 it is asked by the user
+	if g.caFile != "" {
+		ca, err := ioutil.ReadFile(g.caFile)
+		if err != nil {
+			return opt, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return opt, fmt.Errorf("no certificate found in %s", g.caFile)
+		}
+		opt.TLSConfig.RootCAs = pool
+	}
+	if g.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(g.certFile, g.keyFile)
+		if err != nil {
+			return opt, err
+		}
+		opt.TLSConfig.Certificates = []tls.Certificate{cert}
+	}
+	return opt, nil
+}
+
+func (g *globalOptions) client() (*sc.Client, error) {
+	opt, err := g.options()
+	if err != nil {
+		return nil, err
+	}
+	return g.newClient(opt)
+}
+
+func (g *globalOptions) callOptions() []sc.CallOption {
+	if g.global {
+		return []sc.CallOption{sc.WithGlobal()}
+	}
+	return nil
+}
+
+func (g *globalOptions) print(v interface{}, header []string, rows [][]string) error {
+	p, err := newPrinter(g.output, g.stdout)
+	if err != nil {
+		return err
+	}
+	return p.print(v, header, rows)
+}
+
+// parseFlags parses the flags of a command and checks the number of the positional args
+func parseFlags(g *globalOptions, name string, fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	fs.SetOutput(g.stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(g.stderr, "Usage: scctl", commands[name].usage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func newTestOptions() (*globalOptions, *bytes.Buffer) {
+	stdout := &bytes.Buffer{}
+	return &globalOptions{
+		stdout:    stdout,
+		stderr:    &bytes.Buffer{},
+		newClient: sc.NewClient,
+	}, stdout
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/v4/default/registry/microservices":
+			writer.Write([]byte(`{"services":[{"serviceId":"2","appId":"b","serviceName":"s2","version":"1.0.0"},` +
+				`{"serviceId":"1","appId":"a","serviceName":"s1","version":"1.0.0","status":"UP"}]}`))
+		case "/v4/default/govern/apps":
+			writer.Write([]byte(`{"appIds":["b","a"]}`))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	endpoint := server.Listener.Addr().String()
+
+	t.Run("list services as table", func(t *testing.T) {
+		g, stdout := newTestOptions()
+		err := run(g, []string{"-endpoints", endpoint, "services"})
+		assert.NoError(t, err)
+		assert.Equal(t, "ID  ENV  APP  NAME  VERSION  STATUS\n"+
+			"1        a    s1    1.0.0    UP\n"+
+			"2        b    s2    1.0.0    \n", stdout.String())
+	})
+	t.Run("filter services by app", func(t *testing.T) {
+		g, stdout := newTestOptions()
+		err := run(g, []string{"-endpoints", endpoint, "-o", "json", "services", "-app", "b"})
+		assert.NoError(t, err)
+		assert.JSONEq(t, `[{"serviceId":"2","appId":"b","serviceName":"s2","version":"1.0.0"}]`, stdout.String())
+	})
+	t.Run("list apps as yaml", func(t *testing.T) {
+		g, stdout := newTestOptions()
+		err := run(g, []string{"-endpoints", endpoint, "-o", "yaml", "apps"})
+		assert.NoError(t, err)
+		assert.Equal(t, "- a\n- b\n", stdout.String())
+	})
+	t.Run("unknown output format", func(t *testing.T) {
+		g, _ := newTestOptions()
+		err := run(g, []string{"-endpoints", endpoint, "-o", "xml", "apps"})
+		assert.Error(t, err)
+	})
+	t.Run("wrong arguments", func(t *testing.T) {
+		g, _ := newTestOptions()
+		assert.Equal(t, errUsage, run(g, []string{"-endpoints", endpoint, "instances", "app"}))
+		assert.Equal(t, errUsage, run(g, []string{"unknown"}))
+	})
+	t.Run("service center error", func(t *testing.T) {
+		g, _ := newTestOptions()
+		err := run(g, []string{"-endpoints", endpoint, "schemas", "1"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Define the output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+type printer struct {
+	format string
+	w      io.Writer
+}
+
+func newPrinter(format string, w io.Writer) (*printer, error) {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return &printer{format: format, w: w}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q, must be one of table, json and yaml", format)
+}
+
+// print writes v as json or yaml, or the rows as a table
+func (p *printer) print(v interface{}, header []string, rows [][]string) error {
+	switch p.format {
+	case outputJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		return p.yaml(v)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printHeader starts a stream of events, only the table has a header
+func (p *printer) printHeader(header []string) error {
+	if p.format != outputTable {
+		return nil
+	}
+	_, err := fmt.Fprintln(p.w, strings.Join(header, "\t"))
+	return err
+}
+
+// printEvent writes one event of a stream, json events are written one per line
+// and yaml events are separate documents
+func (p *printer) printEvent(v interface{}, row []string) error {
+	switch p.format {
+	case outputJSON:
+		return json.NewEncoder(p.w).Encode(v)
+	case outputYAML:
+		if _, err := fmt.Fprintln(p.w, "---"); err != nil {
+			return err
+		}
+		return p.yaml(v)
+	}
+	_, err := fmt.Fprintln(p.w, strings.Join(row, "\t"))
+	return err
+}
+
+// yaml converts v through json, so the keys are the same as the json output
+func (p *printer) yaml(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic interface{}
+	if err = json.Unmarshal(b, &generic); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(p.w)
+	enc.SetIndent(2)
+	if err = enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
@@ -0,0 +1,324 @@
+package sc
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// ConnKindWatch is the kind of the connections watching micro service instances
+	ConnKindWatch ConnKind = "watch"
+	// ConnKindHeartbeat is the kind of the connections sending instance heartbeat
+	ConnKindHeartbeat ConnKind = "heartbeat"
+)
+
+const (
+	// ConnDialing means the connection is being established
+	ConnDialing ConnState = iota
+	// ConnOpen means the connection is established
+	ConnOpen
+	// ConnClosing means the connection is being closed
+	ConnClosing
+	// ConnBackoff means the connection is broken and waiting for reconnection
+	ConnBackoff
+)
+
+const controlWriteWait = 5 * time.Second
+
+// ErrConnNotExists means the websocket connection is not managed by the client
+var ErrConnNotExists = errors.New("websocket connection does not exist")
+
+// ConnKind is the purpose of a websocket connection
+type ConnKind string
+
+// ConnState is the state of a websocket connection
+type ConnState int
+
+// String returns the name of the state
+func (s ConnState) String() string {
+	switch s {
+	case ConnDialing:
+		return "dialing"
+	case ConnOpen:
+		return "open"
+	case ConnClosing:
+		return "closing"
+	case ConnBackoff:
+		return "backoff"
+	}
+	return "unknown"
+}
+
+// ConnInfo is the diagnostic snapshot of a websocket connection
+type ConnInfo struct {
+	Kind ConnKind
+	// ID is the micro service ID for watch, instance ID for heartbeat
+	ID       string
+	Endpoint string
+	State    ConnState
+	// Since is the time of the last state transition
+	Since      time.Time
+	LastPong   time.Time
+	Reconnects int
+	LastError  string
+}
+
+type connKey struct {
+	kind ConnKind
+	id   string
+}
+
+type managedConn struct {
+	conn *websocket.Conn
+	info ConnInfo
+	stop chan struct{}
+}
+
+// connManager owns every websocket connection of a client
+type connManager struct {
+	mutex        sync.Mutex
+	conns        map[connKey]*managedConn
+	dial         func(u *url.URL) (*websocket.Conn, *http.Response, error)
+	pingInterval time.Duration
+	pongTimeout  time.Duration
+	log          Logger
+}
+
+func newConnManager(dial func(u *url.URL) (*websocket.Conn, *http.Response, error),
+	pingInterval, pongTimeout time.Duration, log Logger) *connManager {
+	if pingInterval > 0 && pongTimeout <= 0 {
+		pongTimeout = 3 * pingInterval
+	}
+	return &connManager{
+		conns:        make(map[connKey]*managedConn),
+		dial:         dial,
+		pingInterval: pingInterval,
+		pongTimeout:  pongTimeout,
+		log:          log,
+	}
+}
+
+// open dials the url and records the connection, an existing connection of the same key is replaced
+func (m *connManager) open(kind ConnKind, id string, u *url.URL) (*websocket.Conn, error) {
+	key := connKey{kind: kind, id: id}
+	m.mutex.Lock()
+	mc, ok := m.conns[key]
+	if !ok {
+		mc = &managedConn{info: ConnInfo{Kind: kind, ID: id}}
+		m.conns[key] = mc
+	} else {
+		mc.info.Reconnects++
+	}
+	old := mc.release()
+	mc.info.Endpoint = u.Host
+	mc.setState(ConnDialing)
+	m.mutex.Unlock()
+	if old != nil {
+		_ = old.Close()
+	}
+
+	conn, _, err := m.dial(u)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.conns[key] != mc {
+		// removed while dialing
+		if conn != nil {
+			_ = conn.Close()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrConnNotExists
+	}
+	if err != nil {
+		mc.info.LastError = err.Error()
+		mc.setState(ConnBackoff)
+		return nil, err
+	}
+	mc.conn = conn
+	mc.info.LastError = ""
+	mc.setState(ConnOpen)
+	if m.pingInterval > 0 {
+		mc.stop = make(chan struct{})
+		m.keepalive(key, conn, mc.stop)
+	}
+	return conn, nil
+}
+
+// keepalive pings the peer every interval and fails the reads if no pong is received in time
+func (m *connManager) keepalive(key connKey, conn *websocket.Conn, stop chan struct{}) {
+	extend := func() {
+		_ = conn.SetReadDeadline(time.Now().Add(m.pongTimeout))
+	}
+	extend()
+	conn.SetPongHandler(func(string) error {
+		extend()
+		m.mutex.Lock()
+		if mc, ok := m.conns[key]; ok && mc.conn == conn {
+			mc.info.LastPong = time.Now()
+		}
+		m.mutex.Unlock()
+		return nil
+	})
+	conn.SetPingHandler(func(data string) error {
+		extend()
+		err := conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(controlWriteWait))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		if e, ok := err.(net.Error); ok && e.Timeout() {
+			return nil
+		}
+		return err
+	})
+	go func() {
+		ticker := time.NewTicker(m.pingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(controlWriteWait))
+				if err != nil {
+					m.log.Debug("websocket ping failed", "kind", key.kind, "id", key.id, "error", err)
+					return
+				}
+			}
+		}
+	}()
+}
+
+// get returns the open connection of the key
+func (m *connManager) get(kind ConnKind, id string) *websocket.Conn {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	mc, ok := m.conns[connKey{kind: kind, id: id}]
+	if !ok {
+		return nil
+	}
+	return mc.conn
+}
+
+// exists reports whether the key is managed, no matter what state it is in
+func (m *connManager) exists(kind ConnKind, id string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, ok := m.conns[connKey{kind: kind, id: id}]
+	return ok
+}
+
+// endpoint returns the address the key was last dialed to
+func (m *connManager) endpoint(kind ConnKind, id string) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	mc, ok := m.conns[connKey{kind: kind, id: id}]
+	if !ok {
+		return ""
+	}
+	return mc.info.Endpoint
+}
+
+// backoff closes the connection and keeps the key waiting for reconnection
+func (m *connManager) backoff(kind ConnKind, id string, cause error) error {
+	m.mutex.Lock()
+	mc, ok := m.conns[connKey{kind: kind, id: id}]
+	if !ok {
+		m.mutex.Unlock()
+		return ErrConnNotExists
+	}
+	conn := mc.release()
+	if cause != nil {
+		mc.info.LastError = cause.Error()
+	}
+	mc.setState(ConnBackoff)
+	m.mutex.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// close closes the connection and forgets the key
+func (m *connManager) close(kind ConnKind, id string) error {
+	key := connKey{kind: kind, id: id}
+	m.mutex.Lock()
+	mc, ok := m.conns[key]
+	if !ok {
+		m.mutex.Unlock()
+		return ErrConnNotExists
+	}
+	mc.setState(ConnClosing)
+	conn := mc.release()
+	delete(m.conns, key)
+	m.mutex.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// closeAll closes every connection, the first error is returned
+func (m *connManager) closeAll() error {
+	m.mutex.Lock()
+	conns := make(map[connKey]*websocket.Conn, len(m.conns))
+	for k, mc := range m.conns {
+		mc.setState(ConnClosing)
+		if conn := mc.release(); conn != nil {
+			conns[k] = conn
+		}
+		delete(m.conns, k)
+	}
+	m.mutex.Unlock()
+	var first error
+	for k, conn := range conns {
+		if err := conn.Close(); err != nil {
+			m.log.Error("close websocket connection failed", "kind", k.kind, "id", k.id, "error", err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// snapshot returns the info of all connections ordered by kind and id
+func (m *connManager) snapshot() []ConnInfo {
+	m.mutex.Lock()
+	infos := make([]ConnInfo, 0, len(m.conns))
+	for _, mc := range m.conns {
+		infos = append(infos, mc.info)
+	}
+	m.mutex.Unlock()
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Kind != infos[j].Kind {
+			return infos[i].Kind < infos[j].Kind
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// release detaches the connection and stops its keepalive, must be called with the lock held
+func (mc *managedConn) release() *websocket.Conn {
+	if mc.stop != nil {
+		close(mc.stop)
+		mc.stop = nil
+	}
+	conn := mc.conn
+	mc.conn = nil
+	return conn
+}
+
+func (mc *managedConn) setState(s ConnState) {
+	mc.info.State = s
+	mc.info.Since = time.Now()
+}
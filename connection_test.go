@@ -0,0 +1,60 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_Connections(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		conn, err := upgrader.Upgrade(writer, request, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			// keep reading so that pings are answered
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer scServer.Close()
+
+	c, err := sc.NewClient(sc.Options{
+		Endpoints:             []string{scServer.Listener.Addr().String()},
+		WebsocketPingInterval: 50 * time.Millisecond,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	err = c.WatchMicroService("sid", func(*sc.MicroServiceInstanceChangedEvent) {})
+	assert.NoError(t, err)
+
+	t.Run("watch connection should be open", func(t *testing.T) {
+		conns := c.Connections()
+		assert.Len(t, conns, 1)
+		assert.Equal(t, sc.ConnKindWatch, conns[0].Kind)
+		assert.Equal(t, "sid", conns[0].ID)
+		assert.Equal(t, sc.ConnOpen, conns[0].State)
+		assert.Equal(t, scServer.Listener.Addr().String(), conns[0].Endpoint)
+	})
+	t.Run("keepalive should receive pongs", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			conns := c.Connections()
+			return len(conns) == 1 && !conns[0].LastPong.IsZero()
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+	t.Run("disconnect should forget the connection", func(t *testing.T) {
+		c.DisconnectMicroServiceWatching("sid")
+		assert.Empty(t, c.Connections())
+	})
+}
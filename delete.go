@@ -0,0 +1,162 @@
+package sc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// DeleteService deletes the service, if force is false,
+// service center refuses to delete the service which still has instances or consumers
+func (c *Client) DeleteService(microServiceID string, force bool) error {
+	var params []URLParameter
+	if force {
+		params = append(params, URLParameter{"force": "1"})
+	}
+	url := c.formatURL(fmt.Sprintf("%s%s/%s", MSAPIPath, MicroservicePath, microServiceID), params, nil)
+	resp, err := c.httpDo("DELETE", url, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return fmt.Errorf("DeleteService failed, response is empty, MicroServiceId: %s", microServiceID)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return NewIOException(err)
+	}
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "\"errorCode\":\"400012\"") {
+		return ErrMicroServiceNotExists
+	}
+	return NewCommonException("result: %d %s", resp.StatusCode, string(body))
+}
+
+// DeleteServices deletes the services in one request,
+// it returns the failure of every service which is not deleted
+func (c *Client) DeleteServices(microServiceIDs []string, force bool) (map[string]error, error) {
+	if len(microServiceIDs) == 0 {
+		return nil, nil
+	}
+	request := &discovery.DelServicesRequest{
+		ServiceIds: microServiceIDs,
+		Force:      force,
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, NewJSONException(err, "parse the DelServicesRequest failed")
+	}
+	url := c.formatURL(MSAPIPath+MicroservicePath, nil, nil)
+	resp, err := c.httpDo("DELETE", url, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("DeleteServices failed, response is empty, MicroServiceIds: %v", microServiceIDs)
+	}
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, NewIOException(err)
+	}
+	if resp.StatusCode == http.StatusOK {
+		return nil, nil
+	}
+	if resp.StatusCode == http.StatusBadRequest {
+		// service center lists the failed services when some of them are deleted
+		var response discovery.DelServicesResponse
+		if err = json.Unmarshal(body, &response); err == nil && len(response.Services) > 0 {
+			failures := make(map[string]error, len(response.Services))
+			for _, s := range response.Services {
+				if s.ErrMessage != "" {
+					failures[s.ServiceId] = NewCommonException("%s", s.ErrMessage)
+				}
+			}
+			return failures, nil
+		}
+	}
+	return nil, NewCommonException("result: %d %s", resp.StatusCode, string(body))
+}
+
+// CleanupOptions selects the services removed by CleanupServices
+type CleanupOptions struct {
+	// MinAge is the minimum time since the service is registered, zero means any age
+	MinAge time.Duration
+	// Filter picks the services which can be removed, nil means all
+	Filter func(*discovery.MicroService) bool
+	// DryRun only reports the services without deleting them
+	DryRun bool
+}
+
+// CleanupReport is the result of CleanupServices, the services are identified by app/name/version
+type CleanupReport struct {
+	// Candidates are the services which have no instance and are old enough
+	Candidates []*discovery.MicroService
+	Deleted    []string
+	Failed     map[string]error
+	DryRun     bool
+}
+
+// CleanupServices removes the services which have no instance and are registered more than MinAge ago,
+// service center itself is never removed
+func (c *Client) CleanupServices(ctx context.Context, opts CleanupOptions) (*CleanupReport, error) {
+	report := &CleanupReport{DryRun: opts.DryRun, Failed: make(map[string]error)}
+	now := time.Now()
+	err := c.EachServiceDetail(func(detail *discovery.ServiceDetail) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s := detail.MicroService
+		if s == nil || len(detail.Instances) > 0 || isServiceCenter(s) {
+			return nil
+		}
+		if opts.Filter != nil && !opts.Filter(s) {
+			return nil
+		}
+		created, err := strconv.ParseInt(s.Timestamp, 10, 64)
+		if err != nil {
+			c.log.Debug("skip the service without valid timestamp", "serviceID", s.ServiceId, "timestamp", s.Timestamp)
+			return nil
+		}
+		if now.Sub(time.Unix(created, 0)) < opts.MinAge {
+			return nil
+		}
+		report.Candidates = append(report.Candidates, s)
+		return nil
+	}, WithGovernOptions(GovernInstances))
+	if err != nil {
+		return report, err
+	}
+	if opts.DryRun || len(report.Candidates) == 0 {
+		return report, nil
+	}
+	ids := make([]string, 0, len(report.Candidates))
+	for _, s := range report.Candidates {
+		ids = append(ids, s.ServiceId)
+	}
+	// instances registered after listing block the deletion, so it is not forced
+	failures, err := c.DeleteServices(ids, false)
+	if err != nil {
+		return report, err
+	}
+	for _, s := range report.Candidates {
+		if failure, ok := failures[s.ServiceId]; ok {
+			report.Failed[serviceKeyString(s)] = failure
+			continue
+		}
+		report.Deleted = append(report.Deleted, serviceKeyString(s))
+	}
+	return report, nil
+}
+
+func isServiceCenter(s *discovery.MicroService) bool {
+	return s.AppId == "default" && s.ServiceName == "SERVICECENTER"
+}
@@ -0,0 +1,133 @@
+package sc_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_DeleteService(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mu.Lock()
+		queries = append(queries, request.URL.RawQuery)
+		mu.Unlock()
+		switch request.URL.Path {
+		case "/v4/default/registry/microservices/sid":
+			writer.WriteHeader(http.StatusOK)
+		case "/v4/default/registry/microservices/busy":
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(`{"errorCode":"400021","errorMessage":"Can not delete this service, other service rely it."}`))
+		default:
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(`{"errorCode":"400012","errorMessage":"Micro-service does not exist."}`))
+		}
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	assert.NoError(t, c.DeleteService("sid", false))
+	assert.NoError(t, c.DeleteService("sid", true))
+	assert.Equal(t, []string{"", "force=1"}, queries)
+	assert.Error(t, c.DeleteService("busy", false))
+	assert.Equal(t, sc.ErrMicroServiceNotExists, c.DeleteService("missing", true))
+}
+
+func TestClient_DeleteServices(t *testing.T) {
+	var request discovery.DelServicesRequest
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/v4/default/registry/microservices", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		request = discovery.DelServicesRequest{}
+		json.Unmarshal(body, &request)
+		if len(request.ServiceIds) == 1 {
+			writer.WriteHeader(http.StatusOK)
+			return
+		}
+		writer.WriteHeader(http.StatusBadRequest)
+		writer.Write([]byte(`{"services":[{"serviceId":"s2","errMessage":"has instances"}]}`))
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	failures, err := c.DeleteServices([]string{"s1"}, true)
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+	assert.True(t, request.Force)
+
+	failures, err = c.DeleteServices([]string{"s1", "s2"}, false)
+	assert.NoError(t, err)
+	assert.Len(t, failures, 1)
+	assert.ErrorContains(t, failures["s2"], "has instances")
+	assert.False(t, request.Force)
+}
+
+func TestClient_CleanupServices(t *testing.T) {
+	old := strconv.FormatInt(time.Now().Add(-48*time.Hour).Unix(), 10)
+	fresh := strconv.FormatInt(time.Now().Unix(), 10)
+	details := []*discovery.ServiceDetail{
+		{MicroService: &discovery.MicroService{ServiceId: "idle", AppId: "a", ServiceName: "idle", Version: "1.0.0", Timestamp: old}},
+		{MicroService: &discovery.MicroService{ServiceId: "busy", AppId: "a", ServiceName: "busy", Version: "1.0.0", Timestamp: old},
+			Instances: []*discovery.MicroServiceInstance{{InstanceId: "i1"}}},
+		{MicroService: &discovery.MicroService{ServiceId: "new", AppId: "a", ServiceName: "new", Version: "1.0.0", Timestamp: fresh}},
+		{MicroService: &discovery.MicroService{ServiceId: "other", AppId: "b", ServiceName: "other", Version: "1.0.0", Timestamp: old}},
+		{MicroService: &discovery.MicroService{ServiceId: "sc", AppId: "default", ServiceName: "SERVICECENTER", Version: "2.0.0", Timestamp: old}},
+	}
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v4/default/govern/microservices":
+			assert.Equal(t, "instances", r.URL.Query().Get("options"))
+			body, _ := json.Marshal(map[string]interface{}{"allServicesDetail": details})
+			writer.Write(body)
+		case "/v4/default/registry/microservices":
+			var request discovery.DelServicesRequest
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, &request)
+			assert.False(t, request.Force)
+			deleted = append(deleted, request.ServiceIds...)
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	opts := sc.CleanupOptions{MinAge: 24 * time.Hour, DryRun: true,
+		Filter: func(s *discovery.MicroService) bool { return s.AppId == "a" }}
+	t.Run("dry run should only report the candidates", func(t *testing.T) {
+		report, err := c.CleanupServices(context.Background(), opts)
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Len(t, report.Candidates, 1)
+		assert.Equal(t, "idle", report.Candidates[0].ServiceId)
+		assert.Empty(t, report.Deleted)
+		assert.Empty(t, deleted)
+	})
+	t.Run("cleanup should delete the candidates", func(t *testing.T) {
+		opts.DryRun, opts.Filter = false, nil
+		report, err := c.CleanupServices(context.Background(), opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a/idle/1.0.0", "b/other/1.0.0"}, report.Deleted)
+		assert.Equal(t, []string{"idle", "other"}, deleted)
+	})
+}
@@ -0,0 +1,242 @@
+package sc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// DiffScope selects what Diff compares, zero means DiffAll
+type DiffScope int
+
+// Define the scopes of Diff
+const (
+	DiffServices DiffScope = 1 << iota
+	DiffSchemas
+	DiffInstances
+	DiffAll = DiffServices | DiffSchemas | DiffInstances
+)
+
+// DiffKind is the kind of the compared entry
+type DiffKind string
+
+// Define the kinds of the compared entries
+const (
+	DiffKindService  DiffKind = "service"
+	DiffKindSchema   DiffKind = "schema"
+	DiffKindInstance DiffKind = "instance"
+)
+
+// DiffEntry is one difference between two service centers,
+// Service is identified by [env/]app/name/version, Key is the schema id or the endpoints of the instance.
+// Field, A and B are only set for the divergent entries
+type DiffEntry struct {
+	Kind    DiffKind `json:"kind"`
+	Service string   `json:"service"`
+	Key     string   `json:"key,omitempty"`
+	Field   string   `json:"field,omitempty"`
+	A       string   `json:"a,omitempty"`
+	B       string   `json:"b,omitempty"`
+}
+
+func (e DiffEntry) String() string {
+	s := string(e.Kind) + " " + e.Service
+	if e.Key != "" {
+		s += " " + e.Key
+	}
+	if e.Field != "" {
+		s += fmt.Sprintf(" %s: %q != %q", e.Field, e.A, e.B)
+	}
+	return s
+}
+
+// DiffReport is the result of Diff,
+// Missing entries exist in a but not in b, Extra entries exist in b but not in a,
+// Divergent entries exist in both with different content
+type DiffReport struct {
+	Missing   []DiffEntry `json:"missing"`
+	Extra     []DiffEntry `json:"extra"`
+	Divergent []DiffEntry `json:"divergent"`
+}
+
+// InSync returns true if there is no difference
+func (r *DiffReport) InSync() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Divergent) == 0
+}
+
+// Diff compares the services, schema summaries and instances of two service centers,
+// for example the two clusters synchronized by syncer.
+// the services are matched by environment, app, name and version, because the ids can be different,
+// the instances are matched by their endpoints
+func Diff(ctx context.Context, a, b *Client, scope DiffScope) (*DiffReport, error) {
+	if scope == 0 {
+		scope = DiffAll
+	}
+	var options []GovernOption
+	if scope&DiffSchemas != 0 {
+		options = append(options, GovernSchemas)
+	}
+	if scope&DiffInstances != 0 {
+		options = append(options, GovernInstances)
+	}
+	left, err := collectServices(ctx, a, options)
+	if err != nil {
+		return nil, fmt.Errorf("query service center a failed: %w", err)
+	}
+	right, err := collectServices(ctx, b, options)
+	if err != nil {
+		return nil, fmt.Errorf("query service center b failed: %w", err)
+	}
+
+	report := &DiffReport{Missing: []DiffEntry{}, Extra: []DiffEntry{}, Divergent: []DiffEntry{}}
+	for _, key := range sortedKeys(left, right) {
+		l, inLeft := left[key]
+		r, inRight := right[key]
+		switch {
+		case !inRight:
+			report.Missing = append(report.Missing, DiffEntry{Kind: DiffKindService, Service: key})
+		case !inLeft:
+			report.Extra = append(report.Extra, DiffEntry{Kind: DiffKindService, Service: key})
+		default:
+			if scope&DiffServices != 0 {
+				report.diffService(key, l.MicroService, r.MicroService)
+			}
+			if scope&DiffSchemas != 0 {
+				report.diffSchemas(key, l.SchemaInfos, r.SchemaInfos)
+			}
+			if scope&DiffInstances != 0 {
+				report.diffInstances(key, l.Instances, r.Instances)
+			}
+		}
+	}
+	return report, nil
+}
+
+func collectServices(ctx context.Context, c *Client, options []GovernOption) (map[string]*discovery.ServiceDetail, error) {
+	services := make(map[string]*discovery.ServiceDetail)
+	err := c.EachServiceDetail(func(detail *discovery.ServiceDetail) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if detail.MicroService != nil {
+			services[serviceKeyString(detail.MicroService)] = detail
+		}
+		return nil
+	}, WithGovernOptions(options...))
+	return services, err
+}
+
+func sortedKeys(maps ...map[string]*discovery.ServiceDetail) []string {
+	set := make(map[string]bool)
+	for _, m := range maps {
+		for k := range m {
+			set[k] = true
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (r *DiffReport) diverge(kind DiffKind, service, key, field, a, b string) {
+	if a == b {
+		return
+	}
+	r.Divergent = append(r.Divergent, DiffEntry{Kind: kind, Service: service, Key: key, Field: field, A: a, B: b})
+}
+
+func (r *DiffReport) diffService(key string, a, b *discovery.MicroService) {
+	r.diverge(DiffKindService, key, "", "status", a.Status, b.Status)
+	r.diverge(DiffKindService, key, "", "level", a.Level, b.Level)
+	r.diverge(DiffKindService, key, "", "description", a.Description, b.Description)
+	r.diverge(DiffKindService, key, "", "properties", formatMap(a.Properties), formatMap(b.Properties))
+}
+
+func (r *DiffReport) diffSchemas(key string, a, b []*discovery.Schema) {
+	left := make(map[string]string, len(a))
+	for _, s := range a {
+		left[s.SchemaId] = s.Summary
+	}
+	right := make(map[string]string, len(b))
+	for _, s := range b {
+		right[s.SchemaId] = s.Summary
+	}
+	for _, id := range sortedStrings(left, right) {
+		l, inLeft := left[id]
+		rs, inRight := right[id]
+		switch {
+		case !inRight:
+			r.Missing = append(r.Missing, DiffEntry{Kind: DiffKindSchema, Service: key, Key: id})
+		case !inLeft:
+			r.Extra = append(r.Extra, DiffEntry{Kind: DiffKindSchema, Service: key, Key: id})
+		default:
+			r.diverge(DiffKindSchema, key, id, "summary", l, rs)
+		}
+	}
+}
+
+func (r *DiffReport) diffInstances(key string, a, b []*discovery.MicroServiceInstance) {
+	left := instancesByEndpoints(a)
+	right := instancesByEndpoints(b)
+	keys := make(map[string]string, len(left)+len(right))
+	for k := range left {
+		keys[k] = ""
+	}
+	for k := range right {
+		keys[k] = ""
+	}
+	for _, endpoints := range sortedStrings(keys) {
+		l, inLeft := left[endpoints]
+		ri, inRight := right[endpoints]
+		switch {
+		case !inRight:
+			r.Missing = append(r.Missing, DiffEntry{Kind: DiffKindInstance, Service: key, Key: endpoints})
+		case !inLeft:
+			r.Extra = append(r.Extra, DiffEntry{Kind: DiffKindInstance, Service: key, Key: endpoints})
+		default:
+			r.diverge(DiffKindInstance, key, endpoints, "status", l.Status, ri.Status)
+			r.diverge(DiffKindInstance, key, endpoints, "properties", formatMap(l.Properties), formatMap(ri.Properties))
+		}
+	}
+}
+
+func instancesByEndpoints(instances []*discovery.MicroServiceInstance) map[string]*discovery.MicroServiceInstance {
+	m := make(map[string]*discovery.MicroServiceInstance, len(instances))
+	for _, i := range instances {
+		endpoints := append([]string(nil), i.Endpoints...)
+		sort.Strings(endpoints)
+		m[strings.Join(endpoints, ",")] = i
+	}
+	return m
+}
+
+func sortedStrings(maps ...map[string]string) []string {
+	set := make(map[string]bool)
+	for _, m := range maps {
+		for k := range m {
+			set[k] = true
+		}
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatMap returns the sorted k=v pairs, so equal maps are equal strings
+func formatMap(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
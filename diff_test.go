@@ -0,0 +1,70 @@
+package sc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func newGovernServer(t *testing.T, body string) *sc.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v4/default/govern/microservices", request.URL.Path)
+		writer.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	return c
+}
+
+func TestDiff(t *testing.T) {
+	a := newGovernServer(t, `{"allServicesDetail":[
+		{"microService":{"serviceId":"1","appId":"app","serviceName":"s1","version":"1.0.0","status":"UP"},
+		 "schemaInfos":[{"schemaId":"hello","summary":"v1"},{"schemaId":"bye","summary":"v1"}],
+		 "instances":[{"instanceId":"i1","endpoints":["rest://1.1.1.1:80"],"status":"UP"},
+		              {"instanceId":"i2","endpoints":["rest://2.2.2.2:80"],"status":"UP"}]},
+		{"microService":{"serviceId":"2","appId":"app","serviceName":"s2","version":"1.0.0"}}]}`)
+	b := newGovernServer(t, `{"allServicesDetail":[
+		{"microService":{"serviceId":"x","appId":"app","serviceName":"s1","version":"1.0.0","status":"DOWN"},
+		 "schemaInfos":[{"schemaId":"hello","summary":"v2"}],
+		 "instances":[{"instanceId":"j1","endpoints":["rest://1.1.1.1:80"],"status":"DOWN"},
+		              {"instanceId":"j3","endpoints":["rest://3.3.3.3:80"],"status":"UP"}]},
+		{"microService":{"serviceId":"3","appId":"app","serviceName":"s3","version":"1.0.0"}}]}`)
+
+	t.Run("compare all", func(t *testing.T) {
+		report, err := sc.Diff(context.Background(), a, b, sc.DiffAll)
+		assert.NoError(t, err)
+		assert.False(t, report.InSync())
+		assert.Equal(t, []sc.DiffEntry{
+			{Kind: sc.DiffKindSchema, Service: "app/s1/1.0.0", Key: "bye"},
+			{Kind: sc.DiffKindInstance, Service: "app/s1/1.0.0", Key: "rest://2.2.2.2:80"},
+			{Kind: sc.DiffKindService, Service: "app/s2/1.0.0"},
+		}, report.Missing)
+		assert.Equal(t, []sc.DiffEntry{
+			{Kind: sc.DiffKindInstance, Service: "app/s1/1.0.0", Key: "rest://3.3.3.3:80"},
+			{Kind: sc.DiffKindService, Service: "app/s3/1.0.0"},
+		}, report.Extra)
+		assert.Equal(t, []sc.DiffEntry{
+			{Kind: sc.DiffKindService, Service: "app/s1/1.0.0", Field: "status", A: "UP", B: "DOWN"},
+			{Kind: sc.DiffKindSchema, Service: "app/s1/1.0.0", Key: "hello", Field: "summary", A: "v1", B: "v2"},
+			{Kind: sc.DiffKindInstance, Service: "app/s1/1.0.0", Key: "rest://1.1.1.1:80", Field: "status", A: "UP", B: "DOWN"},
+		}, report.Divergent)
+	})
+	t.Run("compare services only", func(t *testing.T) {
+		report, err := sc.Diff(context.Background(), a, b, sc.DiffServices)
+		assert.NoError(t, err)
+		assert.Len(t, report.Missing, 1)
+		assert.Len(t, report.Extra, 1)
+		assert.Len(t, report.Divergent, 1)
+	})
+	t.Run("same service center is in sync", func(t *testing.T) {
+		report, err := sc.Diff(context.Background(), a, a, 0)
+		assert.NoError(t, err)
+		assert.True(t, report.InSync())
+	})
+}
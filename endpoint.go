@@ -0,0 +1,152 @@
+package sc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EndpointState is the health of a service center address,
+// it is observed from the requests and the websocket dials, and from the probes if they are enabled.
+// an address which is never used or probed is reported healthy with a zero LastCheck
+type EndpointState struct {
+	Address string
+	// SameAZ is false for the addresses of DiffAzEndpoints
+	SameAZ bool
+	// Active means it served the last successful request
+	Active  bool
+	Healthy bool
+	// CircuitOpen means the circuit breaker rejects the address
+	CircuitOpen bool
+	LastCheck   time.Time
+	LastFailure time.Time
+	LastError   string
+	// Latency is the duration of the last request or probe
+	Latency time.Duration
+}
+
+// endpointTracker records the health of the addresses and the active one
+type endpointTracker struct {
+	mutex    sync.Mutex
+	states   map[string]*EndpointState
+	active   string
+	onChange func(from, to string)
+}
+
+func newEndpointTracker(onChange func(from, to string)) *endpointTracker {
+	return &endpointTracker{
+		states:   make(map[string]*EndpointState),
+		onChange: onChange,
+	}
+}
+
+func (t *endpointTracker) record(address string, err error, latency time.Duration) {
+	if address == "" {
+		return
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s, ok := t.states[address]
+	if !ok {
+		s = &EndpointState{Address: address}
+		t.states[address] = s
+	}
+	s.LastCheck = time.Now()
+	s.Latency = latency
+	s.Healthy = err == nil
+	if err != nil {
+		s.LastFailure = s.LastCheck
+		s.LastError = err.Error()
+	}
+}
+
+// activate marks the address which served a request and calls the hook if it changes
+func (t *endpointTracker) activate(address string) {
+	t.mutex.Lock()
+	from := t.active
+	if from == address {
+		t.mutex.Unlock()
+		return
+	}
+	t.active = address
+	t.mutex.Unlock()
+	if t.onChange != nil {
+		t.onChange(from, address)
+	}
+}
+
+func (t *endpointTracker) get(address string) (EndpointState, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	s, ok := t.states[address]
+	if !ok {
+		return EndpointState{Address: address, Healthy: true, Active: address == t.active}, false
+	}
+	copied := *s
+	copied.Active = address == t.active
+	return copied, true
+}
+
+// EndpointStatus returns the state of every configured address, the same AZ addresses first
+func (c *Client) EndpointStatus() []EndpointState {
+	sameAZAddresses := c.sameAZAddresses()
+	sameAZ := make(map[string]bool, len(sameAZAddresses))
+	for _, e := range sameAZAddresses {
+		sameAZ[e] = true
+	}
+	candidates := c.candidateAddresses()
+	states := make([]EndpointState, 0, len(candidates))
+	for _, address := range candidates {
+		s, _ := c.endpoints.get(address)
+		s.SameAZ = sameAZ[address]
+		if c.breakers != nil && !c.breakers.available(address) {
+			s.CircuitOpen = true
+			s.Healthy = false
+		}
+		states = append(states, s)
+	}
+	return states
+}
+
+// ProbeEndpoints checks the readiness of every configured address at the same time and records the result
+func (c *Client) ProbeEndpoints(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, address := range c.candidateAddresses() {
+		wg.Add(1)
+		go func(address string) {
+			defer wg.Done()
+			c.probeEndpoint(ctx, address)
+		}(address)
+	}
+	wg.Wait()
+}
+
+func (c *Client) probeEndpoint(ctx context.Context, address string) {
+	if c.opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opt.Timeout)
+		defer cancel()
+	}
+	rawURL := c.formatURL(MSAPIPath+ReadinessPath, nil, &CallOptions{Address: address})
+	start := time.Now()
+	resp, err := c.transport.Do(ctx, "GET", rawURL, c.GetDefaultHeaders(), nil)
+	latency := time.Since(start)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	c.endpoints.record(address, responseError(resp, err), latency)
+}
+
+// runEndpointProbes probes the addresses every interval until the client is closed
+func (c *Client) runEndpointProbes(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.ProbeEndpoints(c.ctx)
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
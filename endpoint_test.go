@@ -0,0 +1,108 @@
+package sc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_EndpointStatus(t *testing.T) {
+	alive := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"appIds":["app"]}`))
+	}))
+	defer alive.Close()
+	dead := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {}))
+	deadAddr := dead.Listener.Addr().String()
+	dead.Close()
+	aliveAddr := alive.Listener.Addr().String()
+
+	changes := make(chan [2]string, 4)
+	c, err := sc.NewClient(sc.Options{
+		Endpoints:       []string{deadAddr},
+		DiffAzEndpoints: []string{aliveAddr},
+		Timeout:         time.Second,
+		CircuitBreaker:  &sc.CircuitBreakerOptions{FailureThreshold: 1, OpenTimeout: time.Minute},
+		OnActiveAddressChange: func(from, to string) {
+			changes <- [2]string{from, to}
+		},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	states := c.EndpointStatus()
+	assert.Len(t, states, 2)
+	assert.True(t, states[0].SameAZ)
+	assert.False(t, states[1].SameAZ)
+	assert.True(t, states[1].Healthy)
+	assert.True(t, states[1].LastCheck.IsZero())
+
+	t.Run("probe should record the health and latency", func(t *testing.T) {
+		c.ProbeEndpoints(context.Background())
+		states := c.EndpointStatus()
+		assert.Equal(t, deadAddr, states[0].Address)
+		assert.False(t, states[0].Healthy)
+		assert.NotEmpty(t, states[0].LastError)
+		assert.False(t, states[0].LastFailure.IsZero())
+		assert.Equal(t, aliveAddr, states[1].Address)
+		assert.True(t, states[1].Healthy)
+		assert.False(t, states[1].LastCheck.IsZero())
+		assert.NotZero(t, states[1].Latency)
+	})
+	t.Run("hook should be called when the active address changes", func(t *testing.T) {
+		_, err := c.GetAllApplications()
+		assert.Error(t, err)
+		select {
+		case change := <-changes:
+			t.Errorf("the failed address should not be active, got %v", change)
+		default:
+		}
+		apps, err := c.GetAllApplications()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"app"}, apps)
+		assert.Equal(t, [2]string{"", aliveAddr}, <-changes)
+
+		states := c.EndpointStatus()
+		assert.True(t, states[0].CircuitOpen)
+		assert.False(t, states[0].Active)
+		assert.True(t, states[1].Active)
+	})
+}
+
+func TestOptions_OnActiveAddressChangeWithHedging(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"instances":[{"instanceId":"i1"}]}`))
+	}))
+	defer fast.Close()
+	fastAddr := fast.Listener.Addr().String()
+
+	changes := make(chan [2]string, 4)
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{slow.Listener.Addr().String(), fastAddr},
+		Hedging:   &sc.HedgingOptions{Delay: 20 * time.Millisecond},
+		OnActiveAddressChange: func(from, to string) {
+			changes <- [2]string{from, to}
+		},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	for i := 0; i < 3; i++ {
+		_, err = c.FindInstances("", "default", "provider", sc.WithoutRevision())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, [2]string{"", fastAddr}, <-changes, "only the address which answers should be active")
+	assert.Len(t, changes, 0)
+}
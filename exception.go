@@ -19,6 +19,11 @@ func (e *RegistryException) Error() string {
 	return fmt.Sprintf("%s(%s), %s", e.Title, e.Err.Error(), e.Message)
 }
 
+// Unwrap returns the cause of the exception
+func (e *RegistryException) Unwrap() error {
+	return e.Err
+}
+
 func formatMessage(args []interface{}) string {
 	if len(args) == 0 {
 		return ""
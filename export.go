@@ -0,0 +1,252 @@
+package sc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// RegistryDocumentVersion is the version of the document written by Export
+const RegistryDocumentVersion = 1
+
+const (
+	// ConflictSkip keeps the existing service in the target service center
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite updates the existing service with the imported one
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictFail stops the import at the first existing service
+	ConflictFail ConflictPolicy = "fail"
+)
+
+// ConflictPolicy decides what Import does when a service already exists in the target service center
+type ConflictPolicy string
+
+// RegistryDocument is the versioned content of a service center written by Export
+type RegistryDocument struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exportedAt"`
+	Apps       []string           `json:"apps"`
+	Services   []*ExportedService `json:"services"`
+}
+
+// ExportedService is a micro service with its schemas, tags, providers and instances
+type ExportedService struct {
+	Service   *discovery.MicroService           `json:"service"`
+	Schemas   []*discovery.Schema               `json:"schemas,omitempty"`
+	Tags      map[string]string                 `json:"tags,omitempty"`
+	Providers []*discovery.MicroServiceKey      `json:"providers,omitempty"`
+	Instances []*discovery.MicroServiceInstance `json:"instances,omitempty"`
+}
+
+// ExportOptions is the options of Export
+type ExportOptions struct {
+	WithInstances bool
+}
+
+// ExportOption changes the ExportOptions
+type ExportOption func(*ExportOptions)
+
+// ExportInstances exports the instances as well, they are usually ephemeral
+func ExportInstances() ExportOption {
+	return func(o *ExportOptions) {
+		o.WithInstances = true
+	}
+}
+
+// ImportOptions is the options of Import
+type ImportOptions struct {
+	// Conflict is the policy for the existing services, default is ConflictSkip
+	Conflict ConflictPolicy
+	// WithInstances registers the instances in the document,
+	// they expire unless somebody sends heartbeats for them
+	WithInstances bool
+}
+
+// ImportReport is the result of Import, the services are identified by app/name/version
+type ImportReport struct {
+	Created     []string
+	Overwritten []string
+	Skipped     []string
+	Instances   int
+	// Errors are the failures of schemas, tags, dependencies and instances, they do not stop the import
+	Errors []string
+}
+
+func serviceKeyString(s *discovery.MicroService) string {
+	if s.Environment == "" {
+		return fmt.Sprintf("%s/%s/%s", s.AppId, s.ServiceName, s.Version)
+	}
+	return fmt.Sprintf("%s/%s/%s/%s", s.Environment, s.AppId, s.ServiceName, s.Version)
+}
+
+// Export walks the apps, services, schemas, tags, dependencies and optionally instances,
+// and writes them to w as a RegistryDocument
+func (c *Client) Export(ctx context.Context, w io.Writer, opts ...ExportOption) error {
+	o := &ExportOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	apps, err := c.GetAllApplications()
+	if err != nil {
+		return err
+	}
+	options := []GovernOption{GovernTags, GovernSchemas, GovernDependencies}
+	if o.WithInstances {
+		options = append(options, GovernInstances)
+	}
+	doc := &RegistryDocument{
+		Version:    RegistryDocumentVersion,
+		ExportedAt: time.Now(),
+		Apps:       apps,
+		Services:   []*ExportedService{},
+	}
+	err = c.EachServiceDetail(func(detail *discovery.ServiceDetail) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if detail.MicroService == nil {
+			return nil
+		}
+		s := &ExportedService{
+			Service: detail.MicroService,
+			Schemas: detail.SchemaInfos,
+			Tags:    detail.Tags,
+		}
+		for _, p := range detail.Providers {
+			s.Providers = append(s.Providers, &discovery.MicroServiceKey{
+				Environment: p.Environment,
+				AppId:       p.AppId,
+				ServiceName: p.ServiceName,
+				Version:     p.Version,
+			})
+		}
+		if o.WithInstances {
+			s.Instances = detail.Instances
+		}
+		doc.Services = append(doc.Services, s)
+		return nil
+	}, WithGovernOptions(options...))
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(doc); err != nil {
+		return NewIOException(err, "write registry document failed")
+	}
+	c.log.Info("registry exported", "apps", len(doc.Apps), "services", len(doc.Services))
+	return nil
+}
+
+// Import reads a RegistryDocument from r and recreates its content in service center
+func (c *Client) Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictSkip
+	}
+	doc := &RegistryDocument{}
+	if err := json.NewDecoder(r).Decode(doc); err != nil {
+		return nil, NewJSONException(err, "read registry document failed")
+	}
+	if doc.Version != RegistryDocumentVersion {
+		return nil, NewCommonException("unsupported registry document version %d", doc.Version)
+	}
+	report := &ImportReport{}
+	var dependencies []*discovery.ConsumerDependency
+	for _, s := range doc.Services {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if s.Service == nil {
+			continue
+		}
+		imported, err := c.importService(s, opts, report)
+		if err != nil {
+			return report, err
+		}
+		if !imported {
+			continue
+		}
+		if len(s.Providers) > 0 {
+			dependencies = append(dependencies, &discovery.ConsumerDependency{
+				Consumer: &discovery.MicroServiceKey{
+					Environment: s.Service.Environment,
+					AppId:       s.Service.AppId,
+					ServiceName: s.Service.ServiceName,
+					Version:     s.Service.Version,
+				},
+				Providers: s.Providers,
+			})
+		}
+	}
+	// the providers must exist before the dependencies are added
+	if len(dependencies) > 0 {
+		if err := c.AddDependencies(dependencies); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("add dependencies: %s", err))
+		}
+	}
+	c.log.Info("registry imported", "created", len(report.Created), "overwritten", len(report.Overwritten),
+		"skipped", len(report.Skipped), "instances", report.Instances, "errors", len(report.Errors))
+	return report, nil
+}
+
+// importService creates or overwrites the service, it returns false if the service is skipped
+func (c *Client) importService(s *ExportedService, opts ImportOptions, report *ImportReport) (bool, error) {
+	key := serviceKeyString(s.Service)
+	serviceID, err := c.GetMicroServiceID(s.Service.AppId, s.Service.ServiceName, s.Service.Version, s.Service.Environment)
+	if err != nil {
+		return false, err
+	}
+	if serviceID != "" {
+		switch opts.Conflict {
+		case ConflictSkip:
+			report.Skipped = append(report.Skipped, key)
+			return false, nil
+		case ConflictFail:
+			return false, fmt.Errorf("%w: %s", ErrMicroServiceExists, key)
+		}
+		service := *s.Service
+		service.ServiceId = serviceID
+		if service.Properties == nil {
+			service.Properties = map[string]string{}
+		}
+		if _, err = c.UpdateMicroServiceProperties(serviceID, &service); err != nil {
+			return false, err
+		}
+		report.Overwritten = append(report.Overwritten, key)
+	} else {
+		service := *s.Service
+		serviceID, err = c.RegisterService(&service)
+		if err != nil {
+			return false, err
+		}
+		report.Created = append(report.Created, key)
+	}
+
+	for _, schema := range s.Schemas {
+		if err = c.AddSchemas(serviceID, schema.SchemaId, schema.Schema); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s schema %s: %s", key, schema.SchemaId, err))
+		}
+	}
+	if len(s.Tags) > 0 {
+		if err = c.AddServiceTags(serviceID, s.Tags); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s tags: %s", key, err))
+		}
+	}
+	if !opts.WithInstances {
+		return true, nil
+	}
+	for _, instance := range s.Instances {
+		i := *instance
+		i.ServiceId = serviceID
+		if _, err = c.RegisterMicroServiceInstance(&i); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s instance %s: %s", key, instance.InstanceId, err))
+			continue
+		}
+		report.Instances++
+	}
+	return true, nil
+}
@@ -0,0 +1,108 @@
+package sc_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_ExportImport(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/v4/default/govern/apps":
+			writer.Write([]byte(`{"appIds":["app"]}`))
+		case "/v4/default/govern/microservices":
+			assert.Equal(t, "tags,schemas,dependencies", request.URL.Query().Get("options"))
+			json.NewEncoder(writer).Encode(&discovery.GetServicesInfoResponse{
+				AllServicesDetail: []*discovery.ServiceDetail{
+					{
+						MicroService: &discovery.MicroService{ServiceId: "s1", AppId: "app", ServiceName: "consumer", Version: "1.0.0"},
+						SchemaInfos:  []*discovery.Schema{{SchemaId: "hello", Schema: "swagger"}},
+						Tags:         map[string]string{"team": "a"},
+						Providers:    []*discovery.MicroService{{AppId: "app", ServiceName: "provider", Version: "1.0.0"}},
+					},
+					{
+						MicroService: &discovery.MicroService{ServiceId: "s2", AppId: "app", ServiceName: "provider", Version: "1.0.0"},
+					},
+				},
+			})
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer source.Close()
+
+	var mutex sync.Mutex
+	var calls []string
+	target := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		mutex.Lock()
+		calls = append(calls, request.Method+" "+request.URL.Path)
+		mutex.Unlock()
+		switch request.URL.Path {
+		case "/v4/default/registry/existence":
+			if request.URL.Query().Get("serviceName") == "provider" {
+				writer.Write([]byte(`{"serviceId":"existing"}`))
+				return
+			}
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(`{"errorCode":"400012"}`))
+		case "/v4/default/registry/microservices":
+			body, _ := ioutil.ReadAll(request.Body)
+			req := &discovery.CreateServiceRequest{}
+			json.Unmarshal(body, req)
+			writer.Write([]byte(`{"serviceId":"` + req.Service.ServiceId + `"}`))
+		default:
+			writer.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer target.Close()
+
+	src, err := sc.NewClient(sc.Options{Endpoints: []string{source.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	dst, err := sc.NewClient(sc.Options{Endpoints: []string{target.Listener.Addr().String()}})
+	assert.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	err = src.Export(context.Background(), buf)
+	assert.NoError(t, err)
+
+	doc := &sc.RegistryDocument{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), doc))
+	assert.Equal(t, sc.RegistryDocumentVersion, doc.Version)
+	assert.Equal(t, []string{"app"}, doc.Apps)
+	assert.Len(t, doc.Services, 2)
+
+	t.Run("skip policy should keep existing services", func(t *testing.T) {
+		calls = nil
+		report, err := dst.Import(context.Background(), bytes.NewReader(buf.Bytes()), sc.ImportOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"app/consumer/1.0.0"}, report.Created)
+		assert.Equal(t, []string{"app/provider/1.0.0"}, report.Skipped)
+		assert.Empty(t, report.Errors)
+		assert.Contains(t, calls, "PUT /v4/default/registry/microservices/s1/schemas/hello")
+		assert.Contains(t, calls, "POST /v4/default/registry/microservices/s1/tags")
+		assert.Contains(t, calls, "POST /v4/default/registry/dependencies")
+	})
+	t.Run("overwrite policy should update existing services", func(t *testing.T) {
+		report, err := dst.Import(context.Background(), bytes.NewReader(buf.Bytes()),
+			sc.ImportOptions{Conflict: sc.ConflictOverwrite})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"app/provider/1.0.0"}, report.Overwritten)
+		assert.Contains(t, calls, "PUT /v4/default/registry/microservices/existing/properties")
+	})
+	t.Run("fail policy should stop at existing services", func(t *testing.T) {
+		_, err := dst.Import(context.Background(), bytes.NewReader(buf.Bytes()),
+			sc.ImportOptions{Conflict: sc.ConflictFail})
+		assert.ErrorIs(t, err, sc.ErrMicroServiceExists)
+	})
+}
@@ -3,13 +3,13 @@ module github.com/go-chassis/sc-client
 go 1.20
 
 require (
-	github.com/cenkalti/backoff/v4 v4.1.1
 	github.com/go-chassis/cari v0.9.1-0.20250703032518-a1c3e9de70ad
 	github.com/go-chassis/foundation v0.4.0
 	github.com/go-chassis/openlog v1.1.3
 	github.com/gorilla/websocket v1.4.3-0.20210424162022-e8629af678b7
 	github.com/patrickmn/go-cache v2.1.0+incompatible
 	github.com/stretchr/testify v1.7.2
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -21,5 +21,4 @@ require (
 	github.com/kr/text v0.2.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
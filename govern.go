@@ -0,0 +1,163 @@
+package sc
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// GovernOption selects what the governance API returns with every service
+type GovernOption string
+
+// Define the options of the governance API
+const (
+	GovernInstances    GovernOption = "instances"
+	GovernSchemas      GovernOption = "schemas"
+	GovernTags         GovernOption = "tags"
+	GovernRules        GovernOption = "rules"
+	GovernDependencies GovernOption = "dependencies"
+	GovernStatistics   GovernOption = "statistics"
+	GovernAll          GovernOption = "all"
+)
+
+// GovernOptions joins the options into the resource of GetAllResources
+func GovernOptions(options ...GovernOption) string {
+	resources := make([]string, 0, len(options))
+	for _, o := range options {
+		resources = append(resources, string(o))
+	}
+	return strings.Join(resources, ",")
+}
+
+// governQuery returns the options parameter of the governance API
+func governQuery(options []GovernOption) []URLParameter {
+	if len(options) == 0 {
+		return nil
+	}
+	resources := make([]string, 0, len(options))
+	for _, o := range options {
+		resources = append(resources, string(o))
+	}
+	return []URLParameter{{"options": strings.Join(resources, ",")}}
+}
+
+// GetServiceDetails returns the detail of every micro service from the governance API,
+// use WithGovernOptions to select what is returned with the services
+func (c *Client) GetServiceDetails(opts ...CallOption) ([]*discovery.ServiceDetail, error) {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	return c.getServiceDetails("GetServiceDetails", governQuery(copts.GovernOptions), copts)
+}
+
+func (c *Client) getServiceDetails(op string, querys []URLParameter, copts *CallOptions) ([]*discovery.ServiceDetail, error) {
+	url := c.formatURL(GovernAPIPATH+MicroservicePath, querys, copts)
+	resp, err := c.httpDo("GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("%s failed, response is empty", op)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var response discovery.GetServicesInfoResponse
+		err = c.decodeBody(resp, &response)
+		if err != nil {
+			return nil, err
+		}
+		return response.AllServicesDetail, nil
+	}
+	body, err := c.readBody(resp)
+	if err != nil {
+		return nil, NewIOException(err)
+	}
+	return nil, fmt.Errorf("%s failed, response StatusCode: %d, response body: %s", op, resp.StatusCode, string(body))
+}
+
+// rejectGovernOptions fails the governance calls which can not select the returned resources
+func rejectGovernOptions(op string, copts *CallOptions) error {
+	if len(copts.GovernOptions) > 0 {
+		return fmt.Errorf("%s does not support governance options %v", op, copts.GovernOptions)
+	}
+	return nil
+}
+
+// GetServiceDetail returns the detail of the service, including instances, schemas, tags, rules and dependencies,
+// the detail is always complete, so WithGovernOptions is rejected
+func (c *Client) GetServiceDetail(microServiceID string, opts ...CallOption) (*discovery.ServiceDetail, error) {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	if err := rejectGovernOptions("GetServiceDetail", copts); err != nil {
+		return nil, err
+	}
+	url := c.formatURL(fmt.Sprintf("%s%s/%s", GovernAPIPATH, MicroservicePath, microServiceID), nil, copts)
+	resp, err := c.httpDo("GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("GetServiceDetail failed, response is empty, MicroServiceId: %s", microServiceID)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var response discovery.GetServiceDetailResponse
+		err = c.decodeBody(resp, &response)
+		if err != nil {
+			return nil, err
+		}
+		return response.Service, nil
+	}
+	body, err := c.readBody(resp)
+	if err != nil {
+		return nil, NewIOException(err)
+	}
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(string(body), "\"errorCode\":\"400012\"") {
+		return nil, ErrMicroServiceNotExists
+	}
+	return nil, fmt.Errorf("GetServiceDetail failed, MicroServiceId: %s, response StatusCode: %d, response body: %s",
+		microServiceID, resp.StatusCode, string(body))
+}
+
+// GetStatistics returns the count of the apps, services and instances in the service-center,
+// WithGovernOptions is rejected
+func (c *Client) GetStatistics(opts ...CallOption) (*discovery.Statistics, error) {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	if err := rejectGovernOptions("GetStatistics", copts); err != nil {
+		return nil, err
+	}
+	url := c.formatURL(GovernAPIPATH+MicroservicePath, []URLParameter{
+		{"options": string(GovernStatistics)},
+		{"countOnly": "true"},
+	}, copts)
+	resp, err := c.httpDo("GET", url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil {
+		return nil, errors.New("GetStatistics failed, response is empty")
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		var response discovery.GetServicesInfoResponse
+		err = c.decodeBody(resp, &response)
+		if err != nil {
+			return nil, err
+		}
+		if response.Statistics == nil {
+			return &discovery.Statistics{}, nil
+		}
+		return response.Statistics, nil
+	}
+	body, err := c.readBody(resp)
+	if err != nil {
+		return nil, NewIOException(err)
+	}
+	return nil, fmt.Errorf("GetStatistics failed, response StatusCode: %d, response body: %s", resp.StatusCode, string(body))
+}
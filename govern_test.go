@@ -0,0 +1,99 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestGovernOptions(t *testing.T) {
+	assert.Equal(t, "", sc.GovernOptions())
+	assert.Equal(t, "instances,schemas,tags", sc.GovernOptions(sc.GovernInstances, sc.GovernSchemas, sc.GovernTags))
+}
+
+func TestClient_GetServiceDetails(t *testing.T) {
+	var options []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v4/default/govern/microservices", request.URL.Path)
+		options = append(options, request.URL.Query().Get("options"))
+		writer.Write([]byte(`{"allServicesDetail":[{"microService":{"serviceId":"sid"},"instances":[{"instanceId":"iid"}]}]}`))
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	details, err := c.GetServiceDetails(sc.WithGovernOptions(sc.GovernInstances, sc.GovernSchemas, sc.GovernTags))
+	assert.NoError(t, err)
+	assert.Len(t, details, 1)
+	assert.Equal(t, "iid", details[0].Instances[0].InstanceId)
+
+	var ids []string
+	assert.NoError(t, c.EachServiceDetail(func(detail *discovery.ServiceDetail) error {
+		ids = append(ids, detail.MicroService.ServiceId)
+		return nil
+	}, sc.WithGovernOptions(sc.GovernAll)))
+	assert.Equal(t, []string{"sid"}, ids)
+
+	_, err = c.GetAllResources("dependencies")
+	assert.NoError(t, err)
+	_, err = c.GetServiceDetails()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"instances,schemas,tags", "all", "dependencies", ""}, options)
+}
+
+func TestClient_GetServiceDetail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/v4/default/govern/microservices/sid":
+			writer.Write([]byte(`{"service":{"microService":{"serviceId":"sid","serviceName":"hello"},` +
+				`"instances":[{"instanceId":"iid"}],"tags":{"env":"prod"}}}`))
+		default:
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(`{"errorCode":"400012","errorMessage":"Micro-service does not exist."}`))
+		}
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	detail, err := c.GetServiceDetail("sid")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", detail.MicroService.ServiceName)
+	assert.Len(t, detail.Instances, 1)
+	assert.Equal(t, "prod", detail.Tags["env"])
+
+	_, err = c.GetServiceDetail("missing")
+	assert.Equal(t, sc.ErrMicroServiceNotExists, err)
+	_, err = c.GetServiceDetail("sid", sc.WithGovernOptions(sc.GovernInstances))
+	assert.Error(t, err)
+}
+
+func TestClient_GetStatistics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v4/default/govern/microservices", request.URL.Path)
+		assert.Equal(t, "statistics", request.URL.Query().Get("options"))
+		assert.Equal(t, "true", request.URL.Query().Get("countOnly"))
+		writer.Write([]byte(`{"statistics":{"services":{"count":3,"onlineCount":2},` +
+			`"instances":{"count":5,"countByDomain":7},"apps":{"count":2}}}`))
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	statistics, err := c.GetStatistics()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), statistics.Apps.Count)
+	assert.Equal(t, int64(3), statistics.Services.Count)
+	assert.Equal(t, int64(2), statistics.Services.OnlineCount)
+	assert.Equal(t, int64(5), statistics.Instances.Count)
+	_, err = c.GetStatistics(sc.WithGovernOptions(sc.GovernAll))
+	assert.Error(t, err)
+}
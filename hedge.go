@@ -0,0 +1,162 @@
+package sc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultHedgingDelay is the delay before the hedged request is sent
+const DefaultHedgingDelay = 100 * time.Millisecond
+
+// HedgingOptions enables hedged requests for the idempotent reads, FindInstances and BatchFindInstances.
+// if the current address does not answer within Delay, the same request is sent to another healthy address,
+// the first successful answer wins and the other request is canceled
+type HedgingOptions struct {
+	Delay time.Duration
+}
+
+type hedgeResult struct {
+	index  int
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// cancelOnCloseBody releases the context of the winner request once its body is consumed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.cancel()
+	}
+	return n, err
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// hedgeAddress returns a healthy address other than the primary one, empty if there is none
+func (c *Client) hedgeAddress(primary string) string {
+	for _, candidate := range c.candidateAddresses() {
+		if candidate == primary {
+			continue
+		}
+		if c.breakers != nil && !c.breakers.available(candidate) {
+			continue
+		}
+		return candidate
+	}
+	return ""
+}
+
+// hedgedDo sends an idempotent read, hedging it to a second address when hedging is enabled
+func (c *Client) hedgedDo(method, api string, querys []URLParameter, copts *CallOptions,
+	headers http.Header, body []byte) (*http.Response, error) {
+	if copts == nil {
+		copts = &CallOptions{}
+	}
+	if c.opt.Hedging == nil || len(copts.Address) != 0 {
+		return c.httpDo(method, c.formatURL(api, querys, copts), headers, body)
+	}
+	primary := c.GetAddress()
+	secondary := c.hedgeAddress(primary)
+	if secondary == "" {
+		return c.httpDo(method, c.formatURL(api, querys, copts), headers, body)
+	}
+	delay := c.opt.Hedging.Delay
+	if delay <= 0 {
+		delay = DefaultHedgingDelay
+	}
+
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	send := func(host string) {
+		o := *copts
+		o.Address = host
+		rawURL := c.formatURL(api, querys, &o)
+		ctx, cancel := context.WithCancel(c.ctx)
+		index := len(cancels)
+		cancels = append(cancels, cancel)
+		h := headers.Clone()
+		go func() {
+			resp, err := c.httpDoContext(ctx, method, rawURL, h, body)
+			results <- hedgeResult{index: index, resp: resp, err: err, cancel: cancel}
+		}()
+	}
+	send(primary)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	inflight, hedged := 1, false
+	hedge := func() {
+		if hedged {
+			return
+		}
+		hedged = true
+		inflight++
+		c.log.Debug("hedge request", "url", api, "primary", primary, "secondary", secondary)
+		send(secondary)
+	}
+
+	var failed *hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			hedge()
+		case r := <-results:
+			inflight--
+			if responseError(r.resp, r.err) == nil {
+				if failed != nil {
+					discardHedgeResult(*failed)
+				}
+				// cancel the loser and release it in background
+				for i, cancel := range cancels {
+					if i != r.index {
+						cancel()
+					}
+				}
+				go func(n int) {
+					for i := 0; i < n; i++ {
+						discardHedgeResult(<-results)
+					}
+				}(inflight)
+				if r.resp != nil && r.resp.Body != nil {
+					r.resp.Body = &cancelOnCloseBody{ReadCloser: r.resp.Body, cancel: r.cancel}
+				} else {
+					r.cancel()
+				}
+				return r.resp, r.err
+			}
+			if failed != nil {
+				discardHedgeResult(*failed)
+			}
+			failed = &r
+			if !hedged {
+				// do not wait for the delay if the primary address already failed
+				hedge()
+			}
+			if inflight == 0 {
+				if r.resp != nil && r.resp.Body != nil {
+					r.resp.Body = &cancelOnCloseBody{ReadCloser: r.resp.Body, cancel: r.cancel}
+				} else {
+					r.cancel()
+				}
+				return r.resp, r.err
+			}
+		}
+	}
+}
+
+func discardHedgeResult(r hedgeResult) {
+	r.cancel()
+	if r.resp != nil && r.resp.Body != nil {
+		_ = r.resp.Body.Close()
+	}
+}
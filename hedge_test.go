@@ -0,0 +1,84 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestOptions_Hedging(t *testing.T) {
+	canceled := make(chan struct{}, 1)
+	slowServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+			canceled <- struct{}{}
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slowServer.Close()
+	fastServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set(sc.HeaderRevision, "fast")
+		writer.Write([]byte(`{"instances":[{"instanceId":"i1"}]}`))
+	}))
+	defer fastServer.Close()
+
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{slowServer.Listener.Addr().String(), fastServer.Listener.Addr().String()},
+		Hedging:   &sc.HedgingOptions{Delay: 50 * time.Millisecond},
+	})
+	assert.NoError(t, err)
+
+	start := time.Now()
+	rst, err := c.FindInstances("", "default", "provider")
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, "fast", rst.Revision)
+	assert.Equal(t, "i1", rst.Instances[0].InstanceId)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Error("the slow request should be canceled")
+	}
+}
+
+func TestOptions_HedgingWithCircuitBreaker(t *testing.T) {
+	slowServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slowServer.Close()
+	fastServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"instances":[{"instanceId":"i1"}]}`))
+	}))
+	defer fastServer.Close()
+
+	var opened int32
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{slowServer.Listener.Addr().String(), fastServer.Listener.Addr().String()},
+		Hedging:   &sc.HedgingOptions{Delay: 20 * time.Millisecond},
+		CircuitBreaker: &sc.CircuitBreakerOptions{
+			FailureThreshold: 1,
+			OnStateChange: func(address string, from, to sc.BreakerState) {
+				if to == sc.BreakerOpen {
+					atomic.AddInt32(&opened, 1)
+				}
+			},
+		},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err := c.FindInstances("", "default", "provider", sc.WithoutRevision())
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&opened), "the canceled losers should not open the breaker")
+}
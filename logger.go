@@ -0,0 +1,129 @@
+package sc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-chassis/openlog"
+)
+
+// Level is the severity of a log entry
+type Level int
+
+const (
+	// LevelDebug logs everything, it is the default level
+	LevelDebug Level = iota
+	// LevelInfo logs info, warn and error entries
+	LevelInfo
+	// LevelWarn logs warn and error entries, use it to silence reconnect logs
+	LevelWarn
+	// LevelError logs error entries only
+	LevelError
+	// LevelSilent disables logging
+	LevelSilent
+)
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	case LevelSilent:
+		return "SILENT"
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// Logger is a structured logger, keysAndValues are alternating keys and values,
+// for example Info("connected", "serviceID", id, "endpoint", addr).
+// *slog.Logger satisfies this interface
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// NewOpenlogLogger returns a Logger which writes to the global openlog logger,
+// key value pairs are appended to the message. it is the default logger of the client
+func NewOpenlogLogger() Logger {
+	return openlogLogger{}
+}
+
+type openlogLogger struct{}
+
+func (openlogLogger) Debug(msg string, keysAndValues ...interface{}) {
+	openlog.Debug(formatKV(msg, keysAndValues))
+}
+
+func (openlogLogger) Info(msg string, keysAndValues ...interface{}) {
+	openlog.Info(formatKV(msg, keysAndValues))
+}
+
+func (openlogLogger) Warn(msg string, keysAndValues ...interface{}) {
+	openlog.Warn(formatKV(msg, keysAndValues))
+}
+
+func (openlogLogger) Error(msg string, keysAndValues ...interface{}) {
+	openlog.Error(formatKV(msg, keysAndValues))
+}
+
+func formatKV(msg string, keysAndValues []interface{}) string {
+	if len(keysAndValues) == 0 {
+		return msg
+	}
+	var b strings.Builder
+	b.WriteString(msg)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		b.WriteString(", ")
+		if i+1 == len(keysAndValues) {
+			fmt.Fprintf(&b, "!BADKEY=%v", keysAndValues[i])
+			break
+		}
+		fmt.Fprintf(&b, "%v=%v", keysAndValues[i], keysAndValues[i+1])
+	}
+	return b.String()
+}
+
+// levelLogger drops the entries below the level
+type levelLogger struct {
+	logger Logger
+	level  Level
+}
+
+func newLevelLogger(l Logger, level Level) *levelLogger {
+	if l == nil {
+		l = NewOpenlogLogger()
+	}
+	return &levelLogger{logger: l, level: level}
+}
+
+func (l *levelLogger) Debug(msg string, keysAndValues ...interface{}) {
+	if l.level <= LevelDebug {
+		l.logger.Debug(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) Info(msg string, keysAndValues ...interface{}) {
+	if l.level <= LevelInfo {
+		l.logger.Info(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) Warn(msg string, keysAndValues ...interface{}) {
+	if l.level <= LevelWarn {
+		l.logger.Warn(msg, keysAndValues...)
+	}
+}
+
+func (l *levelLogger) Error(msg string, keysAndValues ...interface{}) {
+	if l.level <= LevelError {
+		l.logger.Error(msg, keysAndValues...)
+	}
+}
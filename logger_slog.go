@@ -0,0 +1,17 @@
+//go:build go1.21
+
+package sc
+
+import (
+	"log/slog"
+)
+
+var _ Logger = (*slog.Logger)(nil)
+
+// NewSlogLogger adapts a slog logger to Logger, nil means slog.Default()
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return l
+}
@@ -0,0 +1,60 @@
+package sc_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+type recordLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *recordLogger) record(level, msg string, kv []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprintf("%s %s %v", level, msg, kv))
+}
+func (l *recordLogger) Debug(msg string, kv ...interface{}) { l.record("DEBUG", msg, kv) }
+func (l *recordLogger) Info(msg string, kv ...interface{})  { l.record("INFO", msg, kv) }
+func (l *recordLogger) Warn(msg string, kv ...interface{})  { l.record("WARN", msg, kv) }
+func (l *recordLogger) Error(msg string, kv ...interface{}) { l.record("ERROR", msg, kv) }
+
+func TestOptions_Logger(t *testing.T) {
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer scServer.Close()
+
+	t.Run("sync failure should be logged with fields", func(t *testing.T) {
+		l := &recordLogger{}
+		c, err := sc.NewClient(sc.Options{
+			Endpoints: []string{scServer.Listener.Addr().String()},
+			Logger:    l,
+		})
+		assert.NoError(t, err)
+		err = c.SyncEndpoints()
+		assert.Error(t, err)
+		assert.Len(t, l.entries, 1)
+		assert.Contains(t, l.entries[0], "ERROR sync endpoints failed [error")
+	})
+	t.Run("entries below the level should be dropped", func(t *testing.T) {
+		l := &recordLogger{}
+		c, err := sc.NewClient(sc.Options{
+			Endpoints: []string{scServer.Listener.Addr().String()},
+			Logger:    l,
+			LogLevel:  sc.LevelSilent,
+		})
+		assert.NoError(t, err)
+		err = c.SyncEndpoints()
+		assert.Error(t, err)
+		assert.Empty(t, l.entries)
+	})
+}
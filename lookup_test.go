@@ -0,0 +1,86 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_Lookup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/v4/default/registry/microservices/sid/instances/iid":
+			assert.Empty(t, request.Header.Get("X-ConsumerId"))
+			writer.Write([]byte(`{"instance":{"instanceId":"iid","serviceId":"sid","status":"UP"}}`))
+		case "/v4/default/registry/microservices/sid/instances/missing":
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(`{"errorCode":"400017","errorMessage":"Instance does not exist."}`))
+		case "/v4/default/registry/microservices/missing/instances/iid":
+			writer.WriteHeader(http.StatusBadRequest)
+			writer.Write([]byte(`{"errorCode":"400012","errorMessage":"Micro-service does not exist."}`))
+		case "/v4/default/registry/existence":
+			query := request.URL.Query()
+			assert.Equal(t, "schema", query.Get("type"))
+			switch {
+			case query.Get("serviceId") != "sid":
+				writer.WriteHeader(http.StatusBadRequest)
+				writer.Write([]byte(`{"errorCode":"400012","errorMessage":"Micro-service does not exist."}`))
+			case query.Get("schemaId") == "hello":
+				writer.Write([]byte(`{"schemaId":"hello","summary":"abc"}`))
+			case query.Get("schemaId") == "legacy":
+				writer.Header().Set("X-Schema-Summary", "def")
+				writer.Write([]byte(`{"schemaId":"legacy"}`))
+			default:
+				writer.WriteHeader(http.StatusBadRequest)
+				writer.Write([]byte(`{"errorCode":"400016","errorMessage":"Schema does not exist."}`))
+			}
+		default:
+			writer.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	t.Run("get instance", func(t *testing.T) {
+		instance, err := c.GetInstance("", "sid", "iid")
+		assert.NoError(t, err)
+		assert.Equal(t, "UP", instance.Status)
+		_, err = c.GetInstance("", "sid", "missing")
+		assert.Equal(t, sc.ErrMicroServiceInstanceNotExists, err)
+		_, err = c.GetInstance("", "missing", "iid")
+		assert.Equal(t, sc.ErrMicroServiceNotExists, err)
+		_, err = c.GetInstance("", "sid", "broken")
+		assert.Error(t, err)
+	})
+	t.Run("instance exists", func(t *testing.T) {
+		ok, err := c.InstanceExists("sid", "iid")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = c.InstanceExists("sid", "missing")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		ok, err = c.InstanceExists("missing", "iid")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		_, err = c.InstanceExists("sid", "broken")
+		assert.Error(t, err)
+	})
+	t.Run("schema exists", func(t *testing.T) {
+		summary, err := c.SchemaExists("sid", "hello")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", summary)
+		summary, err = c.SchemaExists("sid", "legacy")
+		assert.NoError(t, err)
+		assert.Equal(t, "def", summary)
+		_, err = c.SchemaExists("sid", "missing")
+		assert.Equal(t, sc.ErrSchemaNotExists, err)
+		_, err = c.SchemaExists("missing", "hello")
+		assert.Equal(t, sc.ErrMicroServiceNotExists, err)
+	})
+}
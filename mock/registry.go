@@ -0,0 +1,433 @@
+// Package mock provides a hand-written sc.Registry for the unit tests of the code depending on service center
+package mock
+
+import (
+	"sync"
+
+	"github.com/go-chassis/cari/discovery"
+
+	"github.com/go-chassis/sc-client"
+)
+
+var _ sc.Registry = (*Registry)(nil)
+
+// Registry implements sc.Registry by the Func fields, a method whose Func is nil returns the zero values.
+// the calls are counted by method name
+type Registry struct {
+	RegisterServiceFunc                      func(microService *discovery.MicroService) (string, error)
+	UnregisterMicroServiceFunc               func(microServiceID string) (bool, error)
+	DeleteServiceFunc                        func(microServiceID string, force bool) error
+	DeleteServicesFunc                       func(microServiceIDs []string, force bool) (map[string]error, error)
+	UpdateMicroServicePropertiesFunc         func(microServiceID string, microService *discovery.MicroService) (bool, error)
+	PatchServicePropertiesFunc               func(microServiceID string, patch sc.PropertiesPatch) (map[string]string, error)
+	AddServiceTagsFunc                       func(microServiceID string, tags map[string]string) error
+	AddDependenciesFunc                      func(dependencies []*discovery.ConsumerDependency) error
+	RegisterMicroServiceInstanceFunc         func(microServiceInstance *discovery.MicroServiceInstance) (string, error)
+	UnregisterMicroServiceInstanceFunc       func(microServiceID, microServiceInstanceID string) (bool, error)
+	UpdateMicroServiceInstanceStatusFunc     func(microServiceID, microServiceInstanceID, status string) (bool, error)
+	UpdateMicroServiceInstancePropertiesFunc func(microServiceID, microServiceInstanceID string, microServiceInstance *discovery.MicroServiceInstance) (bool, error)
+	PatchInstancePropertiesFunc              func(microServiceID, microServiceInstanceID string, patch sc.PropertiesPatch) (map[string]string, error)
+	HeartbeatFunc                            func(microServiceID, microServiceInstanceID string) (bool, error)
+	WSHeartbeatFunc                          func(microServiceID, microServiceInstanceID string, callback func()) error
+	GetMicroServiceIDFunc                    func(appID, microServiceName, version, env string, opts ...sc.CallOption) (string, error)
+	GetMicroServiceFunc                      func(microServiceID string, opts ...sc.CallOption) (*discovery.MicroService, error)
+	GetAllMicroServicesFunc                  func(opts ...sc.CallOption) ([]*discovery.MicroService, error)
+	GetAllApplicationsFunc                   func(opts ...sc.CallOption) ([]string, error)
+	GetProvidersFunc                         func(consumer string, opts ...sc.CallOption) (*sc.MicroServiceProvideResponse, error)
+	GetAllResourcesFunc                      func(resource string, opts ...sc.CallOption) ([]*discovery.ServiceDetail, error)
+	GetServiceDetailsFunc                    func(opts ...sc.CallOption) ([]*discovery.ServiceDetail, error)
+	GetServiceDetailFunc                     func(microServiceID string, opts ...sc.CallOption) (*discovery.ServiceDetail, error)
+	GetStatisticsFunc                        func(opts ...sc.CallOption) (*discovery.Statistics, error)
+	EachServiceFunc                          func(fn func(*discovery.MicroService) error, opts ...sc.CallOption) error
+	EachServiceDetailFunc                    func(fn func(*discovery.ServiceDetail) error, opts ...sc.CallOption) error
+	FindMicroServiceInstancesFunc            func(consumerID, appID, microServiceName, versionRule string, opts ...sc.CallOption) ([]*discovery.MicroServiceInstance, error)
+	FindInstancesFunc                        func(consumerID, appID, microServiceName string, opts ...sc.CallOption) (*sc.FindMicroServiceInstancesResult, error)
+	BatchFindInstancesFunc                   func(consumerID string, keys []*discovery.FindService, opts ...sc.CallOption) (*discovery.BatchFindInstancesResponse, error)
+	GetMicroServiceInstancesFunc             func(consumerID, providerID string, opts ...sc.CallOption) ([]*discovery.MicroServiceInstance, error)
+	GetInstanceFunc                          func(consumerID, providerID, instanceID string, opts ...sc.CallOption) (*discovery.MicroServiceInstance, error)
+	InstanceExistsFunc                       func(microServiceID, microServiceInstanceID string, opts ...sc.CallOption) (bool, error)
+	WatchMicroServiceFunc                    func(microServiceID string, callback func(*sc.MicroServiceInstanceChangedEvent)) error
+	WatchMicroServiceWithExtraHandleFunc     func(microServiceID string, callback func(e *sc.MicroServiceInstanceChangedEvent), extraHandle func(action string, opts ...sc.CallOption)) error
+	DisconnectMicroServiceWatchingFunc       func(microServiceID string)
+	AddSchemasFunc                           func(microServiceID, schemaName, schemaInfo string) error
+	GetSchemaFunc                            func(microServiceID, schemaName string, opts ...sc.CallOption) ([]byte, error)
+	SchemaExistsFunc                         func(microServiceID, schemaID string, opts ...sc.CallOption) (string, error)
+	CloseFunc                                func() error
+
+	mutex sync.Mutex
+	calls map[string]int
+}
+
+// Calls returns how many times the method is called
+func (m *Registry) Calls(method string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.calls[method]
+}
+
+func (m *Registry) called(method string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.calls == nil {
+		m.calls = make(map[string]int)
+	}
+	m.calls[method]++
+}
+
+// RegisterService calls RegisterServiceFunc
+func (m *Registry) RegisterService(microService *discovery.MicroService) (string, error) {
+	m.called("RegisterService")
+	if m.RegisterServiceFunc != nil {
+		return m.RegisterServiceFunc(microService)
+	}
+	return "", nil
+}
+
+// UnregisterMicroService calls UnregisterMicroServiceFunc
+func (m *Registry) UnregisterMicroService(microServiceID string) (bool, error) {
+	m.called("UnregisterMicroService")
+	if m.UnregisterMicroServiceFunc != nil {
+		return m.UnregisterMicroServiceFunc(microServiceID)
+	}
+	return false, nil
+}
+
+// DeleteService calls DeleteServiceFunc
+func (m *Registry) DeleteService(microServiceID string, force bool) error {
+	m.called("DeleteService")
+	if m.DeleteServiceFunc != nil {
+		return m.DeleteServiceFunc(microServiceID, force)
+	}
+	return nil
+}
+
+// DeleteServices calls DeleteServicesFunc
+func (m *Registry) DeleteServices(microServiceIDs []string, force bool) (map[string]error, error) {
+	m.called("DeleteServices")
+	if m.DeleteServicesFunc != nil {
+		return m.DeleteServicesFunc(microServiceIDs, force)
+	}
+	return nil, nil
+}
+
+// UpdateMicroServiceProperties calls UpdateMicroServicePropertiesFunc
+func (m *Registry) UpdateMicroServiceProperties(microServiceID string, microService *discovery.MicroService) (bool, error) {
+	m.called("UpdateMicroServiceProperties")
+	if m.UpdateMicroServicePropertiesFunc != nil {
+		return m.UpdateMicroServicePropertiesFunc(microServiceID, microService)
+	}
+	return false, nil
+}
+
+// PatchServiceProperties calls PatchServicePropertiesFunc
+func (m *Registry) PatchServiceProperties(microServiceID string, patch sc.PropertiesPatch) (map[string]string, error) {
+	m.called("PatchServiceProperties")
+	if m.PatchServicePropertiesFunc != nil {
+		return m.PatchServicePropertiesFunc(microServiceID, patch)
+	}
+	return nil, nil
+}
+
+// AddServiceTags calls AddServiceTagsFunc
+func (m *Registry) AddServiceTags(microServiceID string, tags map[string]string) error {
+	m.called("AddServiceTags")
+	if m.AddServiceTagsFunc != nil {
+		return m.AddServiceTagsFunc(microServiceID, tags)
+	}
+	return nil
+}
+
+// AddDependencies calls AddDependenciesFunc
+func (m *Registry) AddDependencies(dependencies []*discovery.ConsumerDependency) error {
+	m.called("AddDependencies")
+	if m.AddDependenciesFunc != nil {
+		return m.AddDependenciesFunc(dependencies)
+	}
+	return nil
+}
+
+// RegisterMicroServiceInstance calls RegisterMicroServiceInstanceFunc
+func (m *Registry) RegisterMicroServiceInstance(microServiceInstance *discovery.MicroServiceInstance) (string, error) {
+	m.called("RegisterMicroServiceInstance")
+	if m.RegisterMicroServiceInstanceFunc != nil {
+		return m.RegisterMicroServiceInstanceFunc(microServiceInstance)
+	}
+	return "", nil
+}
+
+// UnregisterMicroServiceInstance calls UnregisterMicroServiceInstanceFunc
+func (m *Registry) UnregisterMicroServiceInstance(microServiceID, microServiceInstanceID string) (bool, error) {
+	m.called("UnregisterMicroServiceInstance")
+	if m.UnregisterMicroServiceInstanceFunc != nil {
+		return m.UnregisterMicroServiceInstanceFunc(microServiceID, microServiceInstanceID)
+	}
+	return false, nil
+}
+
+// UpdateMicroServiceInstanceStatus calls UpdateMicroServiceInstanceStatusFunc
+func (m *Registry) UpdateMicroServiceInstanceStatus(microServiceID, microServiceInstanceID, status string) (bool, error) {
+	m.called("UpdateMicroServiceInstanceStatus")
+	if m.UpdateMicroServiceInstanceStatusFunc != nil {
+		return m.UpdateMicroServiceInstanceStatusFunc(microServiceID, microServiceInstanceID, status)
+	}
+	return false, nil
+}
+
+// UpdateMicroServiceInstanceProperties calls UpdateMicroServiceInstancePropertiesFunc
+func (m *Registry) UpdateMicroServiceInstanceProperties(microServiceID, microServiceInstanceID string,
+	microServiceInstance *discovery.MicroServiceInstance) (bool, error) {
+	m.called("UpdateMicroServiceInstanceProperties")
+	if m.UpdateMicroServiceInstancePropertiesFunc != nil {
+		return m.UpdateMicroServiceInstancePropertiesFunc(microServiceID, microServiceInstanceID, microServiceInstance)
+	}
+	return false, nil
+}
+
+// PatchInstanceProperties calls PatchInstancePropertiesFunc
+func (m *Registry) PatchInstanceProperties(microServiceID, microServiceInstanceID string,
+	patch sc.PropertiesPatch) (map[string]string, error) {
+	m.called("PatchInstanceProperties")
+	if m.PatchInstancePropertiesFunc != nil {
+		return m.PatchInstancePropertiesFunc(microServiceID, microServiceInstanceID, patch)
+	}
+	return nil, nil
+}
+
+// Heartbeat calls HeartbeatFunc
+func (m *Registry) Heartbeat(microServiceID, microServiceInstanceID string) (bool, error) {
+	m.called("Heartbeat")
+	if m.HeartbeatFunc != nil {
+		return m.HeartbeatFunc(microServiceID, microServiceInstanceID)
+	}
+	return false, nil
+}
+
+// WSHeartbeat calls WSHeartbeatFunc
+func (m *Registry) WSHeartbeat(microServiceID, microServiceInstanceID string, callback func()) error {
+	m.called("WSHeartbeat")
+	if m.WSHeartbeatFunc != nil {
+		return m.WSHeartbeatFunc(microServiceID, microServiceInstanceID, callback)
+	}
+	return nil
+}
+
+// GetMicroServiceID calls GetMicroServiceIDFunc
+func (m *Registry) GetMicroServiceID(appID, microServiceName, version, env string, opts ...sc.CallOption) (string, error) {
+	m.called("GetMicroServiceID")
+	if m.GetMicroServiceIDFunc != nil {
+		return m.GetMicroServiceIDFunc(appID, microServiceName, version, env, opts...)
+	}
+	return "", nil
+}
+
+// GetMicroService calls GetMicroServiceFunc
+func (m *Registry) GetMicroService(microServiceID string, opts ...sc.CallOption) (*discovery.MicroService, error) {
+	m.called("GetMicroService")
+	if m.GetMicroServiceFunc != nil {
+		return m.GetMicroServiceFunc(microServiceID, opts...)
+	}
+	return nil, nil
+}
+
+// GetAllMicroServices calls GetAllMicroServicesFunc
+func (m *Registry) GetAllMicroServices(opts ...sc.CallOption) ([]*discovery.MicroService, error) {
+	m.called("GetAllMicroServices")
+	if m.GetAllMicroServicesFunc != nil {
+		return m.GetAllMicroServicesFunc(opts...)
+	}
+	return nil, nil
+}
+
+// GetAllApplications calls GetAllApplicationsFunc
+func (m *Registry) GetAllApplications(opts ...sc.CallOption) ([]string, error) {
+	m.called("GetAllApplications")
+	if m.GetAllApplicationsFunc != nil {
+		return m.GetAllApplicationsFunc(opts...)
+	}
+	return nil, nil
+}
+
+// GetProviders calls GetProvidersFunc
+func (m *Registry) GetProviders(consumer string, opts ...sc.CallOption) (*sc.MicroServiceProvideResponse, error) {
+	m.called("GetProviders")
+	if m.GetProvidersFunc != nil {
+		return m.GetProvidersFunc(consumer, opts...)
+	}
+	return nil, nil
+}
+
+// GetAllResources calls GetAllResourcesFunc
+func (m *Registry) GetAllResources(resource string, opts ...sc.CallOption) ([]*discovery.ServiceDetail, error) {
+	m.called("GetAllResources")
+	if m.GetAllResourcesFunc != nil {
+		return m.GetAllResourcesFunc(resource, opts...)
+	}
+	return nil, nil
+}
+
+// GetServiceDetails calls GetServiceDetailsFunc
+func (m *Registry) GetServiceDetails(opts ...sc.CallOption) ([]*discovery.ServiceDetail, error) {
+	m.called("GetServiceDetails")
+	if m.GetServiceDetailsFunc != nil {
+		return m.GetServiceDetailsFunc(opts...)
+	}
+	return nil, nil
+}
+
+// GetServiceDetail calls GetServiceDetailFunc
+func (m *Registry) GetServiceDetail(microServiceID string, opts ...sc.CallOption) (*discovery.ServiceDetail, error) {
+	m.called("GetServiceDetail")
+	if m.GetServiceDetailFunc != nil {
+		return m.GetServiceDetailFunc(microServiceID, opts...)
+	}
+	return nil, nil
+}
+
+// GetStatistics calls GetStatisticsFunc
+func (m *Registry) GetStatistics(opts ...sc.CallOption) (*discovery.Statistics, error) {
+	m.called("GetStatistics")
+	if m.GetStatisticsFunc != nil {
+		return m.GetStatisticsFunc(opts...)
+	}
+	return nil, nil
+}
+
+// EachService calls EachServiceFunc
+func (m *Registry) EachService(fn func(*discovery.MicroService) error, opts ...sc.CallOption) error {
+	m.called("EachService")
+	if m.EachServiceFunc != nil {
+		return m.EachServiceFunc(fn, opts...)
+	}
+	return nil
+}
+
+// EachServiceDetail calls EachServiceDetailFunc
+func (m *Registry) EachServiceDetail(fn func(*discovery.ServiceDetail) error, opts ...sc.CallOption) error {
+	m.called("EachServiceDetail")
+	if m.EachServiceDetailFunc != nil {
+		return m.EachServiceDetailFunc(fn, opts...)
+	}
+	return nil
+}
+
+// FindMicroServiceInstances calls FindMicroServiceInstancesFunc
+func (m *Registry) FindMicroServiceInstances(consumerID, appID, microServiceName, versionRule string,
+	opts ...sc.CallOption) ([]*discovery.MicroServiceInstance, error) {
+	m.called("FindMicroServiceInstances")
+	if m.FindMicroServiceInstancesFunc != nil {
+		return m.FindMicroServiceInstancesFunc(consumerID, appID, microServiceName, versionRule, opts...)
+	}
+	return nil, nil
+}
+
+// FindInstances calls FindInstancesFunc
+func (m *Registry) FindInstances(consumerID, appID, microServiceName string,
+	opts ...sc.CallOption) (*sc.FindMicroServiceInstancesResult, error) {
+	m.called("FindInstances")
+	if m.FindInstancesFunc != nil {
+		return m.FindInstancesFunc(consumerID, appID, microServiceName, opts...)
+	}
+	return nil, nil
+}
+
+// BatchFindInstances calls BatchFindInstancesFunc
+func (m *Registry) BatchFindInstances(consumerID string, keys []*discovery.FindService,
+	opts ...sc.CallOption) (*discovery.BatchFindInstancesResponse, error) {
+	m.called("BatchFindInstances")
+	if m.BatchFindInstancesFunc != nil {
+		return m.BatchFindInstancesFunc(consumerID, keys, opts...)
+	}
+	return nil, nil
+}
+
+// GetMicroServiceInstances calls GetMicroServiceInstancesFunc
+func (m *Registry) GetMicroServiceInstances(consumerID, providerID string,
+	opts ...sc.CallOption) ([]*discovery.MicroServiceInstance, error) {
+	m.called("GetMicroServiceInstances")
+	if m.GetMicroServiceInstancesFunc != nil {
+		return m.GetMicroServiceInstancesFunc(consumerID, providerID, opts...)
+	}
+	return nil, nil
+}
+
+// GetInstance calls GetInstanceFunc
+func (m *Registry) GetInstance(consumerID, providerID, instanceID string,
+	opts ...sc.CallOption) (*discovery.MicroServiceInstance, error) {
+	m.called("GetInstance")
+	if m.GetInstanceFunc != nil {
+		return m.GetInstanceFunc(consumerID, providerID, instanceID, opts...)
+	}
+	return nil, nil
+}
+
+// InstanceExists calls InstanceExistsFunc
+func (m *Registry) InstanceExists(microServiceID, microServiceInstanceID string, opts ...sc.CallOption) (bool, error) {
+	m.called("InstanceExists")
+	if m.InstanceExistsFunc != nil {
+		return m.InstanceExistsFunc(microServiceID, microServiceInstanceID, opts...)
+	}
+	return false, nil
+}
+
+// WatchMicroService calls WatchMicroServiceFunc
+func (m *Registry) WatchMicroService(microServiceID string, callback func(*sc.MicroServiceInstanceChangedEvent)) error {
+	m.called("WatchMicroService")
+	if m.WatchMicroServiceFunc != nil {
+		return m.WatchMicroServiceFunc(microServiceID, callback)
+	}
+	return nil
+}
+
+// WatchMicroServiceWithExtraHandle calls WatchMicroServiceWithExtraHandleFunc
+func (m *Registry) WatchMicroServiceWithExtraHandle(microServiceID string, callback func(e *sc.MicroServiceInstanceChangedEvent),
+	extraHandle func(action string, opts ...sc.CallOption)) error {
+	m.called("WatchMicroServiceWithExtraHandle")
+	if m.WatchMicroServiceWithExtraHandleFunc != nil {
+		return m.WatchMicroServiceWithExtraHandleFunc(microServiceID, callback, extraHandle)
+	}
+	return nil
+}
+
+// DisconnectMicroServiceWatching calls DisconnectMicroServiceWatchingFunc
+func (m *Registry) DisconnectMicroServiceWatching(microServiceID string) {
+	m.called("DisconnectMicroServiceWatching")
+	if m.DisconnectMicroServiceWatchingFunc != nil {
+		m.DisconnectMicroServiceWatchingFunc(microServiceID)
+	}
+}
+
+// AddSchemas calls AddSchemasFunc
+func (m *Registry) AddSchemas(microServiceID, schemaName, schemaInfo string) error {
+	m.called("AddSchemas")
+	if m.AddSchemasFunc != nil {
+		return m.AddSchemasFunc(microServiceID, schemaName, schemaInfo)
+	}
+	return nil
+}
+
+// GetSchema calls GetSchemaFunc
+func (m *Registry) GetSchema(microServiceID, schemaName string, opts ...sc.CallOption) ([]byte, error) {
+	m.called("GetSchema")
+	if m.GetSchemaFunc != nil {
+		return m.GetSchemaFunc(microServiceID, schemaName, opts...)
+	}
+	return nil, nil
+}
+
+// SchemaExists calls SchemaExistsFunc
+func (m *Registry) SchemaExists(microServiceID, schemaID string, opts ...sc.CallOption) (string, error) {
+	m.called("SchemaExists")
+	if m.SchemaExistsFunc != nil {
+		return m.SchemaExistsFunc(microServiceID, schemaID, opts...)
+	}
+	return "", nil
+}
+
+// Close calls CloseFunc
+func (m *Registry) Close() error {
+	m.called("Close")
+	if m.CloseFunc != nil {
+		return m.CloseFunc()
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+	"github.com/go-chassis/sc-client/mock"
+)
+
+// register is the code under test, it only depends on the role interface
+func register(r sc.Registrar, instance *discovery.MicroServiceInstance) (string, error) {
+	return r.RegisterMicroServiceInstance(instance)
+}
+
+func TestRegistry(t *testing.T) {
+	m := &mock.Registry{
+		RegisterMicroServiceInstanceFunc: func(instance *discovery.MicroServiceInstance) (string, error) {
+			return "instance-" + instance.HostName, nil
+		},
+	}
+	id, err := register(m, &discovery.MicroServiceInstance{HostName: "host"})
+	assert.NoError(t, err)
+	assert.Equal(t, "instance-host", id)
+	assert.Equal(t, 1, m.Calls("RegisterMicroServiceInstance"))
+
+	rst, err := m.FindInstances("", "app", "service")
+	assert.NoError(t, err)
+	assert.Nil(t, rst)
+	assert.Equal(t, 1, m.Calls("FindInstances"))
+
+	m.PatchInstancePropertiesFunc = func(_, _ string, patch sc.PropertiesPatch) (map[string]string, error) {
+		return patch.Set, nil
+	}
+	var registrar sc.Registrar = m
+	properties, err := registrar.PatchInstanceProperties("sid", "iid", sc.PropertiesPatch{Set: map[string]string{"k": "v"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"k": "v"}, properties)
+	assert.Equal(t, 1, m.Calls("PatchInstanceProperties"))
+	assert.Equal(t, 0, m.Calls("Close"))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-chassis/cari/rbac"
+	"github.com/gorilla/websocket"
 )
 
 // Options is the list of dynamic parameter's which can be passed to the Client while creating a new client
@@ -25,6 +26,49 @@ type Options struct {
 	AuthToken       string
 	TokenExpiration time.Duration
 	SignRequest     func(*http.Request) error
+	// Transport replaces the default transport, the options of the default transport are ignored,
+	// such as TLSConfig, Compressed, Timeout, the authentication, RoundTripper and WebsocketDialer
+	Transport Transport
+	// RoundTripper replaces the http.Transport of the default transport, for example an instrumented one,
+	// the TLSConfig is not applied to it
+	RoundTripper http.RoundTripper
+	// WebsocketDialer replaces the websocket dialer of the default transport
+	WebsocketDialer *websocket.Dialer
+	// Logger receives the diagnostics of the client, default is openlog
+	Logger Logger
+	// LogLevel drops log entries below it, default is LevelDebug
+	LogLevel Level
+	// WebsocketPingInterval is the interval of the keepalive pings sent on websocket connections,
+	// zero disables the keepalive
+	WebsocketPingInterval time.Duration
+	// WebsocketPongTimeout closes a websocket connection when no pong is received in time,
+	// default is 3 times of WebsocketPingInterval
+	WebsocketPongTimeout time.Duration
+	// ReconnectScheduler retries the broken websocket connections, default is DefaultReconnectScheduler
+	ReconnectScheduler *ReconnectScheduler
+	// RateLimit limits the requests sent to service center, nil means unlimited
+	RateLimit *RateLimitOptions
+	// CircuitBreaker opens the circuit of a failing service center address, nil disables it
+	CircuitBreaker *CircuitBreakerOptions
+	// Hedging sends the slow instance queries to a second address, nil disables it
+	Hedging *HedgingOptions
+	// MaxResponseSize limits the size of the response bodies in bytes, zero means unlimited
+	MaxResponseSize int64
+	// Snapshot persists the found instances to a local file as the fallback of outage, nil disables it
+	Snapshot *SnapshotOptions
+	// EndpointResolver locates the same AZ addresses instead of Endpoints,
+	// Endpoints are used if the first resolution fails, Client.Reset stops the resolution
+	EndpointResolver EndpointResolver
+	// ResolveInterval is the interval of the resolution, default is DefaultResolveInterval
+	ResolveInterval time.Duration
+	// SyncEndpointsInterval calls SyncEndpoints in background with jitter, zero disables it
+	SyncEndpointsInterval time.Duration
+	// EndpointProbeInterval probes the readiness of all the addresses for EndpointStatus,
+	// zero means the status is only observed from the requests
+	EndpointProbeInterval time.Duration
+	// OnActiveAddressChange is called when the address which serves the requests changes,
+	// the active address is the one of the last successful request or websocket dial
+	OnActiveAddressChange func(from, to string)
 }
 
 // CallOptions is options when you call a API
@@ -33,6 +77,10 @@ type CallOptions struct {
 	Revision        string
 	WithGlobal      bool
 	Address         string
+	// VersionRule is only used by FindInstances
+	VersionRule VersionRule
+	// GovernOptions is only used by GetServiceDetails and EachServiceDetail, GetServiceDetail and GetStatistics reject it
+	GovernOptions []GovernOption
 }
 
 // WithoutRevision ignore current revision number
@@ -63,5 +111,12 @@ func WithAddress(address string) CallOption {
 	}
 }
 
+// WithGovernOptions selects what the governance API returns with every service
+func WithGovernOptions(options ...GovernOption) CallOption {
+	return func(o *CallOptions) {
+		o.GovernOptions = append(o.GovernOptions, options...)
+	}
+}
+
 // CallOption is receiver for options and chang the attribute of it
 type CallOption func(*CallOptions)
@@ -0,0 +1,211 @@
+package sc
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// DefaultPatchRetries is how many times a patch is retried on conflict
+const DefaultPatchRetries = 5
+
+// ErrPatchConflict means the properties keep being changed by others while patching
+var ErrPatchConflict = errors.New("properties are changed concurrently, patch is given up")
+
+// patchBackoff spreads the retries of the concurrent patches
+var patchBackoff = BackoffPolicy{InitialInterval: 20 * time.Millisecond, MaxInterval: time.Second, Multiplier: 2}
+
+// PropertiesPatch is the changes of the properties, Delete is applied after Set
+type PropertiesPatch struct {
+	Set    map[string]string
+	Delete []string
+	// Retries is how many times the patch is retried on conflict, default is DefaultPatchRetries
+	Retries int
+}
+
+// apply returns the patched copy of the properties
+func (p PropertiesPatch) apply(properties map[string]string) map[string]string {
+	patched := make(map[string]string, len(properties)+len(p.Set))
+	for k, v := range properties {
+		patched[k] = v
+	}
+	for k, v := range p.Set {
+		patched[k] = v
+	}
+	for _, k := range p.Delete {
+		delete(patched, k)
+	}
+	return patched
+}
+
+// appliedTo returns true if the changes are in the properties
+func (p PropertiesPatch) appliedTo(properties map[string]string) bool {
+	for k, v := range p.Set {
+		if current, ok := properties[k]; (!ok || current != v) && !p.deletes(k) {
+			return false
+		}
+	}
+	for _, k := range p.Delete {
+		if _, ok := properties[k]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (p PropertiesPatch) deletes(key string) bool {
+	for _, k := range p.Delete {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+type keyedLock struct {
+	sync.Mutex
+	holders int
+}
+
+// keyedMutex serializes the callers of the same key,
+// the lock of a key is removed when its last holder releases it, so the churning keys are not kept
+type keyedMutex struct {
+	mutex sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// lock blocks until the key is locked, the returned function unlocks it
+func (m *keyedMutex) lock(key string) func() {
+	m.mutex.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyedLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		m.locks[key] = l
+	}
+	l.holders++
+	m.mutex.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mutex.Lock()
+		l.holders--
+		if l.holders == 0 {
+			delete(m.locks, key)
+		}
+		m.mutex.Unlock()
+	}
+}
+
+// propertiesResource reads and writes the properties of a service or an instance
+type propertiesResource struct {
+	key   string
+	read  func() (properties map[string]string, modTimestamp string, err error)
+	write func(properties map[string]string) error
+}
+
+func sameProperties(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if current, ok := b[k]; !ok || current != v {
+			return false
+		}
+	}
+	return true
+}
+
+// patchProperties is a best-effort merge of the patch into the current properties.
+// the patches of the same resource through one Client are serialized, so they never lose each other.
+// service center has no conditional update, the changes by other processes are only detected
+// by reading the properties again right before writing and after writing, and the patch is retried on them,
+// but a write of another process landing between the check and the write is still overwritten
+func (c *Client) patchProperties(r propertiesResource, patch PropertiesPatch) (map[string]string, error) {
+	defer c.patchLocks.lock(r.key)()
+	retries := patch.Retries
+	if retries <= 0 {
+		retries = DefaultPatchRetries
+	}
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(patchBackoff.Delay(attempt - 1))
+		}
+		properties, mod, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		if patch.appliedTo(properties) {
+			return properties, nil
+		}
+		patched := patch.apply(properties)
+		// the modification timestamp is in seconds, so the properties are compared as well
+		latest, latestMod, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		if latestMod != mod || !sameProperties(latest, properties) {
+			c.log.Debug("properties changed before patching, retry", "key", r.key, "attempt", attempt)
+			continue
+		}
+		if err = r.write(patched); err != nil {
+			return nil, err
+		}
+		written, _, err := r.read()
+		if err != nil {
+			return nil, err
+		}
+		if patch.appliedTo(written) {
+			return written, nil
+		}
+		c.log.Debug("properties overwritten after patching, retry", "key", r.key, "attempt", attempt)
+	}
+	return nil, ErrPatchConflict
+}
+
+// PatchInstanceProperties sets and deletes the properties of the instance and keeps the others,
+// it returns the properties after patching. it is a best-effort merge, see patchProperties for the limits
+// against the writers in other processes
+func (c *Client) PatchInstanceProperties(microServiceID, microServiceInstanceID string,
+	patch PropertiesPatch) (map[string]string, error) {
+	return c.patchProperties(propertiesResource{
+		key: "instance/" + microServiceID + "/" + microServiceInstanceID,
+		read: func() (map[string]string, string, error) {
+			instance, err := c.GetInstance("", microServiceID, microServiceInstanceID, WithoutRevision())
+			if err != nil {
+				return nil, "", err
+			}
+			return instance.Properties, instance.ModTimestamp, nil
+		},
+		write: func(properties map[string]string) error {
+			_, err := c.UpdateMicroServiceInstanceProperties(microServiceID, microServiceInstanceID,
+				&discovery.MicroServiceInstance{Properties: properties})
+			return err
+		},
+	}, patch)
+}
+
+// PatchServiceProperties sets and deletes the properties of the service and keeps the others,
+// it returns the properties after patching. it is a best-effort merge, see patchProperties for the limits
+// against the writers in other processes
+func (c *Client) PatchServiceProperties(microServiceID string, patch PropertiesPatch) (map[string]string, error) {
+	return c.patchProperties(propertiesResource{
+		key: "service/" + microServiceID,
+		read: func() (map[string]string, string, error) {
+			service, err := c.GetMicroService(microServiceID, WithoutRevision())
+			if err != nil {
+				return nil, "", err
+			}
+			return service.Properties, service.ModTimestamp, nil
+		},
+		write: func(properties map[string]string) error {
+			_, err := c.UpdateMicroServiceProperties(microServiceID, &discovery.MicroService{Properties: properties})
+			return err
+		},
+	}, patch)
+}
@@ -0,0 +1,153 @@
+package sc_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+// propertiesServer keeps the properties of one service and one instance,
+// touch is called on every read to simulate the changes made by others
+type propertiesServer struct {
+	mu         sync.Mutex
+	properties map[string]string
+	mod        int
+	touch      func(s *propertiesServer)
+}
+
+func (s *propertiesServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch request.Method {
+	case http.MethodGet:
+		if s.touch != nil {
+			s.touch(s)
+		}
+		properties := map[string]string{}
+		for k, v := range s.properties {
+			properties[k] = v
+		}
+		mod := strconv.Itoa(s.mod)
+		var body []byte
+		switch request.URL.Path {
+		case "/v4/default/registry/microservices/sid":
+			body, _ = json.Marshal(&discovery.GetServiceResponse{
+				Service: &discovery.MicroService{ServiceId: "sid", Properties: properties, ModTimestamp: mod}})
+		case "/v4/default/registry/microservices/sid/instances/iid":
+			body, _ = json.Marshal(&discovery.GetOneInstanceResponse{
+				Instance: &discovery.MicroServiceInstance{InstanceId: "iid", Properties: properties, ModTimestamp: mod}})
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.Write(body)
+	case http.MethodPut:
+		body, _ := ioutil.ReadAll(request.Body)
+		var req struct {
+			Properties map[string]string `json:"properties"`
+		}
+		json.Unmarshal(body, &req)
+		s.properties = req.Properties
+		s.mod++
+	}
+}
+
+func TestClient_PatchProperties(t *testing.T) {
+	fake := &propertiesServer{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	patch := sc.PropertiesPatch{Set: map[string]string{"weight": "10"}, Delete: []string{"canary"}}
+	patchers := map[string]func() (map[string]string, error){
+		"instance": func() (map[string]string, error) { return c.PatchInstanceProperties("sid", "iid", patch) },
+		"service":  func() (map[string]string, error) { return c.PatchServiceProperties("sid", patch) },
+	}
+	for name, do := range patchers {
+		t.Run(name+" properties should be patched and others are kept", func(t *testing.T) {
+			fake.properties, fake.touch = map[string]string{"owner": "team-a", "canary": "true"}, nil
+			properties, err := do()
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"owner": "team-a", "weight": "10"}, properties)
+			assert.Equal(t, properties, fake.properties)
+		})
+		t.Run(name+" patch should be retried when properties are changed by others", func(t *testing.T) {
+			fake.properties = map[string]string{"owner": "team-a"}
+			changes := 0
+			fake.touch = func(s *propertiesServer) {
+				if changes < 2 {
+					changes++
+					s.properties["version"+strconv.Itoa(changes)] = "x"
+					s.mod++
+				}
+			}
+			properties, err := do()
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]string{"owner": "team-a", "weight": "10",
+				"version1": "x", "version2": "x"}, properties)
+		})
+		t.Run(name+" patch should be retried when properties are changed in the same second", func(t *testing.T) {
+			fake.properties = map[string]string{"owner": "team-a"}
+			changed := false
+			fake.touch = func(s *propertiesServer) {
+				if !changed {
+					changed = true
+					s.properties["version"] = "x"
+				}
+			}
+			properties, err := do()
+			assert.NoError(t, err)
+			assert.Equal(t, "x", properties["version"])
+		})
+		t.Run(name+" patch should give up when properties keep changing", func(t *testing.T) {
+			fake.properties = map[string]string{"owner": "team-a"}
+			fake.touch = func(s *propertiesServer) { s.mod++ }
+			patch.Retries = 2
+			_, err := do()
+			assert.Equal(t, sc.ErrPatchConflict, err)
+			patch.Retries = 0
+		})
+	}
+}
+
+func TestClient_PatchPropertiesConcurrently(t *testing.T) {
+	fake := &propertiesServer{properties: map[string]string{}}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodPut {
+			// widen the window between the check and the write
+			time.Sleep(5 * time.Millisecond)
+		}
+		fake.ServeHTTP(writer, request)
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := c.PatchInstanceProperties("sid", "iid",
+				sc.PropertiesPatch{Set: map[string]string{"key" + strconv.Itoa(i): "v"}, Retries: 1})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	assert.Len(t, fake.properties, 8, "no patch should be lost")
+}
@@ -0,0 +1,238 @@
+package sc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultPeerMonitorInterval is the default interval of polling the syncer health
+const DefaultPeerMonitorInterval = 10 * time.Second
+
+// PeerKind is the kind of the syncer peer
+type PeerKind string
+
+// PeerMode is the synchronization mode of the syncer peer
+type PeerMode string
+
+// PeerStatus is the connection status of the syncer peer
+type PeerStatus string
+
+// Define the kinds, modes and statuses reported by syncer
+const (
+	PeerKindServiceComb PeerKind = "servicecomb"
+
+	PeerModePush PeerMode = "push"
+	PeerModePull PeerMode = "pull"
+
+	// PeerStatusUnknown is used for the peers which are not reported
+	PeerStatusUnknown   PeerStatus = ""
+	PeerStatusConnected PeerStatus = "CONNECTED"
+	PeerStatusAbnormal  PeerStatus = "ABNORMAL"
+	PeerStatusClosed    PeerStatus = "CLOSE"
+)
+
+// Healthy returns true if the peer is connected
+func (s PeerStatus) Healthy() bool {
+	return s == PeerStatusConnected
+}
+
+func (s PeerStatus) String() string {
+	if s == PeerStatusUnknown {
+		return "UNKNOWN"
+	}
+	return string(s)
+}
+
+// PeerStatusResp is the response of the syncer health API
+type PeerStatusResp struct {
+	Peers []*Peer `json:"peers"`
+}
+
+// Peer is a syncer peer of the service center
+type Peer struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"`
+	Mode      []string `json:"mode"`
+	Endpoints []string `json:"endpoints"`
+	Status    string   `json:"status"`
+}
+
+// PeerKind returns the typed Kind
+func (p *Peer) PeerKind() PeerKind {
+	return PeerKind(p.Kind)
+}
+
+// PeerModes returns the typed Mode
+func (p *Peer) PeerModes() []PeerMode {
+	modes := make([]PeerMode, 0, len(p.Mode))
+	for _, m := range p.Mode {
+		modes = append(modes, PeerMode(m))
+	}
+	return modes
+}
+
+// PeerStatus returns the typed Status
+func (p *Peer) PeerStatus() PeerStatus {
+	return PeerStatus(p.Status)
+}
+
+// PeerEvent is a status transition of a syncer peer,
+// From is PeerStatusUnknown for a new peer and To is PeerStatusUnknown for a peer which disappears
+type PeerEvent struct {
+	Name string
+	From PeerStatus
+	To   PeerStatus
+	// Peer is the current state, it is nil if the peer disappears
+	Peer *Peer
+	Time time.Time
+}
+
+// PeerMonitorOptions is the options of the PeerMonitor
+type PeerMonitorOptions struct {
+	// Interval is the polling interval, default is DefaultPeerMonitorInterval
+	Interval time.Duration
+	// OnChange is called with every transition in the polling goroutine
+	OnChange func(PeerEvent)
+	// OnError is called when the syncer health can not be checked, the last state is kept
+	OnError func(error)
+}
+
+// PeerMonitor polls the syncer health of service center, keeps the last state
+// and notifies the status transitions of the peers by callback and channel
+type PeerMonitor struct {
+	c      *Client
+	opts   PeerMonitorOptions
+	events chan PeerEvent
+
+	mutex  sync.Mutex
+	last   map[string]*Peer
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPeerMonitor creates a monitor of the syncer peers, call Start to begin polling
+func (c *Client) NewPeerMonitor(opts PeerMonitorOptions) *PeerMonitor {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultPeerMonitorInterval
+	}
+	return &PeerMonitor{
+		c:      c,
+		opts:   opts,
+		events: make(chan PeerEvent, 16),
+		last:   make(map[string]*Peer),
+	}
+}
+
+// Events returns the channel of the transitions, the events are dropped if nobody reads the channel
+func (m *PeerMonitor) Events() <-chan PeerEvent {
+	return m.events
+}
+
+// Start polls the syncer health immediately and then every interval until Stop or the client is closed,
+// a stopped monitor can be started again
+func (m *PeerMonitor) Start() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.cancel != nil {
+		return
+	}
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(m.c.ctx)
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+}
+
+// Stop stops polling
+func (m *PeerMonitor) Stop() {
+	m.mutex.Lock()
+	cancel, done := m.cancel, m.done
+	m.mutex.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	m.mutex.Lock()
+	if m.done == done {
+		m.cancel, m.done = nil, nil
+	}
+	m.mutex.Unlock()
+}
+
+// Peers returns the last known state of the peers
+func (m *PeerMonitor) Peers() []*Peer {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	peers := make([]*Peer, 0, len(m.last))
+	for _, p := range m.last {
+		copied := *p
+		peers = append(peers, &copied)
+	}
+	return peers
+}
+
+func (m *PeerMonitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	for {
+		m.poll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll checks the syncer health and notifies the transitions
+func (m *PeerMonitor) poll() {
+	resp, err := m.c.CheckPeerStatus()
+	if err != nil {
+		m.c.log.Warn("check peer status failed", "error", err)
+		if m.opts.OnError != nil {
+			m.opts.OnError(err)
+		}
+		return
+	}
+	now := time.Now()
+	current := make(map[string]*Peer)
+	if resp != nil {
+		for _, p := range resp.Peers {
+			if p != nil {
+				current[p.Name] = p
+			}
+		}
+	}
+	m.mutex.Lock()
+	var events []PeerEvent
+	for name, p := range current {
+		from := PeerStatusUnknown
+		if old, ok := m.last[name]; ok {
+			from = old.PeerStatus()
+		}
+		if _, ok := m.last[name]; !ok || from != p.PeerStatus() {
+			events = append(events, PeerEvent{Name: name, From: from, To: p.PeerStatus(), Peer: p, Time: now})
+		}
+	}
+	for name, old := range m.last {
+		if _, ok := current[name]; !ok {
+			events = append(events, PeerEvent{Name: name, From: old.PeerStatus(), To: PeerStatusUnknown, Time: now})
+		}
+	}
+	m.last = current
+	m.mutex.Unlock()
+
+	for _, e := range events {
+		m.c.log.Info("peer status changed", "peer", e.Name, "from", e.From, "to", e.To)
+		if m.opts.OnChange != nil {
+			m.opts.OnChange(e)
+		}
+		select {
+		case m.events <- e:
+		default:
+			m.c.log.Warn("peer event is dropped, the channel is full", "peer", e.Name)
+		}
+	}
+}
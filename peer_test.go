@@ -0,0 +1,65 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestPeerMonitor(t *testing.T) {
+	var status atomic.Value
+	status.Store("CONNECTED")
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, sc.PeerHealthPath, request.URL.Path)
+		writer.Write([]byte(`{"peers":[{"name":"peer","kind":"servicecomb","mode":["push"],` +
+			`"endpoints":["127.0.0.1:30105"],"status":"` + status.Load().(string) + `"}]}`))
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	changes := make(chan sc.PeerEvent, 4)
+	m := c.NewPeerMonitor(sc.PeerMonitorOptions{
+		Interval: 10 * time.Millisecond,
+		OnChange: func(e sc.PeerEvent) {
+			changes <- e
+		},
+	})
+	m.Start()
+	defer m.Stop()
+
+	e := <-m.Events()
+	assert.Equal(t, "peer", e.Name)
+	assert.Equal(t, sc.PeerStatusUnknown, e.From)
+	assert.Equal(t, sc.PeerStatusConnected, e.To)
+	assert.Equal(t, []sc.PeerMode{sc.PeerModePush}, e.Peer.PeerModes())
+	assert.Equal(t, sc.PeerKindServiceComb, e.Peer.PeerKind())
+	assert.Equal(t, "push", e.Peer.Mode[0])
+	assert.Equal(t, e, <-changes)
+
+	status.Store("ABNORMAL")
+	e = <-m.Events()
+	assert.Equal(t, sc.PeerStatusConnected, e.From)
+	assert.Equal(t, sc.PeerStatusAbnormal, e.To)
+	assert.False(t, e.To.Healthy())
+	assert.Len(t, m.Peers(), 1)
+	<-changes
+
+	m.Stop()
+	status.Store("CONNECTED")
+	m.Start()
+	select {
+	case e = <-m.Events():
+	case <-time.After(time.Second):
+		t.Fatal("the restarted monitor should poll again")
+	}
+	assert.Equal(t, sc.PeerStatusAbnormal, e.From)
+	assert.Equal(t, sc.PeerStatusConnected, e.To)
+}
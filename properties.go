@@ -0,0 +1,304 @@
+package sc
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PropertiesTag is the struct tag read by UnmarshalProperties and MarshalProperties,
+// e.g. `properties:"weight,omitempty"`, the field name is the key if there is no tag, "-" skips the field.
+// the fields of an embedded struct without tag name are promoted like encoding/json does
+const PropertiesTag = "properties"
+
+// PropertiesSeparator joins the elements of a slice field into one property value,
+// the spaces around the elements are trimmed by UnmarshalProperties,
+// so MarshalProperties refuses the elements containing the separator or the surrounding spaces
+const PropertiesSeparator = ","
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type propertyField struct {
+	key       string
+	index     []int
+	omitempty bool
+}
+
+// propertyFields returns the fields of the struct, the fields of the embedded structs are promoted,
+// a shallower field hides the deeper ones with the same key, and the ambiguous ones in the same depth are ignored
+func propertyFields(t reflect.Type) []propertyField {
+	var all []propertyField
+	collectPropertyFields(t, nil, &all)
+	depth := make(map[string]int)
+	count := make(map[string]int)
+	for _, f := range all {
+		d, ok := depth[f.key]
+		switch {
+		case !ok || len(f.index) < d:
+			depth[f.key], count[f.key] = len(f.index), 1
+		case len(f.index) == d:
+			count[f.key]++
+		}
+	}
+	var fields []propertyField
+	for _, f := range all {
+		if len(f.index) == depth[f.key] && count[f.key] == 1 {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+func collectPropertyFields(t reflect.Type, index []int, fields *[]propertyField) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get(PropertiesTag)
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		fieldIndex := append(append([]int{}, index...), i)
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectPropertyFields(ft, fieldIndex, fields)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		*fields = append(*fields, propertyField{key: name, index: fieldIndex, omitempty: opts == "omitempty"})
+	}
+}
+
+// fieldByIndex returns the field, the nil embedded struct pointers are allocated if alloc is true,
+// otherwise false is returned for them
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !alloc {
+					return reflect.Value{}, false, nil
+				}
+				if !v.CanSet() {
+					return reflect.Value{}, false, fmt.Errorf("can not set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true, nil
+}
+
+func structValue(v interface{}, op string) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if op == "unmarshal" {
+		if rv.Kind() != reflect.Ptr || rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%s properties: need a non-nil struct pointer, got %T", op, v)
+		}
+	}
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}, fmt.Errorf("%s properties: nil %T", op, v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("%s properties: need a struct, got %T", op, v)
+	}
+	return rv, nil
+}
+
+// UnmarshalProperties sets the fields of the struct pointed by v from the properties,
+// strings, ints, uints, floats, bools, time.Duration, encoding.TextUnmarshaler
+// and slices of them (separated by PropertiesSeparator, the elements are trimmed) are supported,
+// missing keys keep the field unchanged
+func UnmarshalProperties(properties map[string]string, v interface{}) error {
+	rv, err := structValue(v, "unmarshal")
+	if err != nil {
+		return err
+	}
+	for _, f := range propertyFields(rv.Type()) {
+		s, ok := properties[f.key]
+		if !ok {
+			continue
+		}
+		fv, _, err := fieldByIndex(rv, f.index, true)
+		if err != nil {
+			return fmt.Errorf("unmarshal property %s: %w", f.key, err)
+		}
+		if err := setProperty(fv, s); err != nil {
+			return fmt.Errorf("unmarshal property %s=%q: %w", f.key, s, err)
+		}
+	}
+	return nil
+}
+
+// DecodeProperties returns a T filled from the properties, see UnmarshalProperties
+func DecodeProperties[T any](properties map[string]string) (T, error) {
+	var v T
+	err := UnmarshalProperties(properties, &v)
+	return v, err
+}
+
+// MarshalProperties converts the fields of the struct into properties,
+// it is the reverse of UnmarshalProperties, fields with omitempty are skipped if they are zero
+func MarshalProperties(v interface{}) (map[string]string, error) {
+	rv, err := structValue(v, "marshal")
+	if err != nil {
+		return nil, err
+	}
+	properties := make(map[string]string)
+	for _, f := range propertyFields(rv.Type()) {
+		fv, ok, _ := fieldByIndex(rv, f.index, false)
+		if !ok || (f.omitempty && fv.IsZero()) {
+			continue
+		}
+		s, err := formatProperty(fv)
+		if err != nil {
+			return nil, fmt.Errorf("marshal property %s: %w", f.key, err)
+		}
+		properties[f.key] = s
+	}
+	return properties, nil
+}
+
+// MergeProperties marshals the struct and sets the result into properties, the other keys are kept.
+// it is used to fill discovery.MicroServiceInstance.Properties before registering or updating
+func MergeProperties(properties map[string]string, v interface{}) (map[string]string, error) {
+	marshaled, err := MarshalProperties(v)
+	if err != nil {
+		return nil, err
+	}
+	if properties == nil {
+		return marshaled, nil
+	}
+	for k, s := range marshaled {
+		properties[k] = s
+	}
+	return properties, nil
+}
+
+func setProperty(v reflect.Value, s string) error {
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalerType) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if s == "" {
+			v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			return nil
+		}
+		parts := strings.Split(s, PropertiesSeparator)
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setProperty(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setProperty(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+func formatProperty(v reflect.Value) (string, error) {
+	if v.Type().Implements(textMarshalerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return "", nil
+		}
+		b, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(b), err
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String(), nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			s, err := formatProperty(v.Index(i))
+			if err != nil {
+				return "", err
+			}
+			if strings.Contains(s, PropertiesSeparator) || strings.TrimSpace(s) != s {
+				return "", fmt.Errorf("element %q can not be joined by %q", s, PropertiesSeparator)
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, PropertiesSeparator), nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "", nil
+		}
+		return formatProperty(v.Elem())
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
@@ -0,0 +1,135 @@
+package sc_test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+type routeProperties struct {
+	Weight   int           `properties:"weight"`
+	Canary   bool          `properties:"canary"`
+	Timeout  time.Duration `properties:"timeout,omitempty"`
+	Zones    []string      `properties:"zones,omitempty"`
+	Ports    []uint16      `properties:"ports,omitempty"`
+	Ratio    float64       `properties:"ratio,omitempty"`
+	Gateway  net.IP        `properties:"gateway,omitempty"`
+	Owner    *string       `properties:"owner,omitempty"`
+	Version  string
+	Internal string `properties:"-"`
+}
+
+func TestUnmarshalProperties(t *testing.T) {
+	properties := map[string]string{
+		"weight":   "10",
+		"canary":   "true",
+		"timeout":  "1.5s",
+		"zones":    "az1, az2",
+		"ports":    "80,443",
+		"ratio":    "0.25",
+		"gateway":  "10.0.0.1",
+		"owner":    "team-a",
+		"Version":  "1.0.0",
+		"Internal": "x",
+		"unknown":  "y",
+	}
+	t.Run("properties should be converted into the fields", func(t *testing.T) {
+		r, err := sc.DecodeProperties[routeProperties](properties)
+		assert.NoError(t, err)
+		owner := "team-a"
+		assert.Equal(t, routeProperties{Weight: 10, Canary: true, Timeout: 1500 * time.Millisecond,
+			Zones: []string{"az1", "az2"}, Ports: []uint16{80, 443}, Ratio: 0.25,
+			Gateway: net.ParseIP("10.0.0.1"), Owner: &owner, Version: "1.0.0"}, r)
+	})
+	t.Run("missing keys should keep the fields", func(t *testing.T) {
+		r := routeProperties{Weight: 5}
+		assert.NoError(t, sc.UnmarshalProperties(map[string]string{"canary": "true"}, &r))
+		assert.Equal(t, routeProperties{Weight: 5, Canary: true}, r)
+	})
+	t.Run("invalid value should return error with the key", func(t *testing.T) {
+		var r routeProperties
+		err := sc.UnmarshalProperties(map[string]string{"ports": "80,x"}, &r)
+		assert.ErrorContains(t, err, "ports")
+	})
+	t.Run("non pointer should return error", func(t *testing.T) {
+		assert.Error(t, sc.UnmarshalProperties(properties, routeProperties{}))
+	})
+}
+
+func TestMarshalProperties(t *testing.T) {
+	owner := "team-a"
+	r := routeProperties{Weight: 10, Timeout: time.Second, Zones: []string{"az1", "az2"},
+		Gateway: net.ParseIP("10.0.0.1"), Owner: &owner, Version: "1.0.0", Internal: "x"}
+	properties, err := sc.MarshalProperties(&r)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"weight": "10", "canary": "false", "timeout": "1s",
+		"zones": "az1,az2", "gateway": "10.0.0.1", "owner": "team-a", "Version": "1.0.0"}, properties)
+
+	decoded, err := sc.DecodeProperties[routeProperties](properties)
+	assert.NoError(t, err)
+	r.Internal = ""
+	assert.Equal(t, r, decoded)
+
+	t.Run("merge should keep the other keys", func(t *testing.T) {
+		merged, err := sc.MergeProperties(map[string]string{"weight": "1", "env": "prod"}, r)
+		assert.NoError(t, err)
+		assert.Equal(t, "prod", merged["env"])
+		assert.Equal(t, "10", merged["weight"])
+	})
+}
+
+type Labels struct {
+	Zone   string `properties:"zone"`
+	Weight int    `properties:"labelWeight"`
+}
+
+type Meta struct {
+	Owner string `properties:"owner"`
+}
+
+type embeddedProperties struct {
+	Labels
+	*Meta
+	Weight int `properties:"weight"`
+}
+
+func TestProperties_Embedded(t *testing.T) {
+	properties := map[string]string{"zone": "az1", "labelWeight": "3", "owner": "team-a", "weight": "10"}
+	t.Run("fields of embedded structs should be promoted", func(t *testing.T) {
+		v, err := sc.DecodeProperties[embeddedProperties](properties)
+		assert.NoError(t, err)
+		assert.Equal(t, "az1", v.Zone)
+		assert.Equal(t, 3, v.Labels.Weight)
+		assert.Equal(t, "team-a", v.Owner)
+		assert.Equal(t, 10, v.Weight)
+
+		marshaled, err := sc.MarshalProperties(v)
+		assert.NoError(t, err)
+		assert.Equal(t, properties, marshaled)
+	})
+	t.Run("nil embedded pointer should be skipped by marshal", func(t *testing.T) {
+		marshaled, err := sc.MarshalProperties(embeddedProperties{Weight: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"zone": "", "labelWeight": "0", "weight": "1"}, marshaled)
+	})
+}
+
+func TestMarshalProperties_Separator(t *testing.T) {
+	type zones struct {
+		Zones []string `properties:"zones"`
+	}
+	_, err := sc.MarshalProperties(zones{Zones: []string{"az1,az2"}})
+	assert.ErrorContains(t, err, "zones")
+	_, err = sc.MarshalProperties(zones{Zones: []string{" az1"}})
+	assert.Error(t, err)
+
+	marshaled, err := sc.MarshalProperties(zones{Zones: []string{"az1", "az2"}})
+	assert.NoError(t, err)
+	decoded, err := sc.DecodeProperties[zones](marshaled)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"az1", "az2"}, decoded.Zones)
+}
@@ -0,0 +1,230 @@
+package sc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// OperationRead is the class of the discovery and query requests
+	OperationRead OperationClass = "read"
+	// OperationWrite is the class of the registration and update requests
+	OperationWrite OperationClass = "write"
+	// OperationHeartbeat is the class of the heartbeat requests
+	OperationHeartbeat OperationClass = "heartbeat"
+)
+
+// DefaultRetryAfter is the pause of an operation class when service center answers 429 without Retry-After
+const DefaultRetryAfter = 1 * time.Second
+
+// OperationClass groups the API calls sharing one rate limit budget
+type OperationClass string
+
+// RateLimit is a token bucket, zero QPS means unlimited
+type RateLimit struct {
+	QPS float64
+	// Burst is the capacity of the bucket, default is 1
+	Burst int
+}
+
+// RateLimitOptions limits the requests sent to service center
+type RateLimitOptions struct {
+	Read      RateLimit
+	Write     RateLimit
+	Heartbeat RateLimit
+	// FailFast returns RateLimitError instead of waiting for a token
+	FailFast bool
+}
+
+// RateLimitError is returned when the request is rejected by the client side rate limiter
+type RateLimitError struct {
+	Class OperationClass
+	// RetryAfter is the time to wait before the next token is available
+	RetryAfter time.Duration
+}
+
+// Error gets the Error message from the Error
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s operation is rate limited, retry after %v", e.Class, e.RetryAfter)
+}
+
+type tokenBucket struct {
+	qps    float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(l RateLimit) *tokenBucket {
+	if l.QPS <= 0 {
+		return nil
+	}
+	burst := float64(l.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{qps: l.QPS, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve takes a token and returns how long the caller must wait for it,
+// if take is false and the token is not available, nothing is taken
+func (b *tokenBucket) reserve(now time.Time, take bool) time.Duration {
+	b.tokens += now.Sub(b.last).Seconds() * b.qps
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	wait := time.Duration((1 - b.tokens) / b.qps * float64(time.Second))
+	if take {
+		b.tokens--
+	}
+	return wait
+}
+
+// refund returns a reserved token which is not used
+func (b *tokenBucket) refund() {
+	b.tokens++
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// rateLimiter keeps one token bucket and one pause deadline per operation class
+type rateLimiter struct {
+	mutex    sync.Mutex
+	failFast bool
+	buckets  map[OperationClass]*tokenBucket
+	paused   map[OperationClass]time.Time
+}
+
+func newRateLimiter(opt *RateLimitOptions) *rateLimiter {
+	if opt == nil {
+		return nil
+	}
+	return &rateLimiter{
+		failFast: opt.FailFast,
+		buckets: map[OperationClass]*tokenBucket{
+			OperationRead:      newTokenBucket(opt.Read),
+			OperationWrite:     newTokenBucket(opt.Write),
+			OperationHeartbeat: newTokenBucket(opt.Heartbeat),
+		},
+		paused: make(map[OperationClass]time.Time),
+	}
+}
+
+// wait blocks until the class is allowed to send a request, or fails fast
+func (l *rateLimiter) wait(ctx context.Context, class OperationClass) error {
+	l.mutex.Lock()
+	now := time.Now()
+	var wait time.Duration
+	if until, ok := l.paused[class]; ok {
+		if until.After(now) {
+			wait = until.Sub(now)
+		} else {
+			delete(l.paused, class)
+		}
+	}
+	if wait > 0 && l.failFast {
+		l.mutex.Unlock()
+		return &RateLimitError{Class: class, RetryAfter: wait}
+	}
+	b := l.buckets[class]
+	if b != nil {
+		d := b.reserve(now.Add(wait), !l.failFast)
+		if d > 0 && l.failFast {
+			l.mutex.Unlock()
+			return &RateLimitError{Class: class, RetryAfter: d}
+		}
+		wait += d
+	}
+	l.mutex.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		if b != nil {
+			// the canceled caller does not send the request, so it must not delay the later ones
+			l.mutex.Lock()
+			b.refund()
+			l.mutex.Unlock()
+		}
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// observe pauses the class when service center asks the client to slow down
+func (l *rateLimiter) observe(class OperationClass, resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return
+	}
+	d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return
+		}
+		d = DefaultRetryAfter
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	until := time.Now().Add(d)
+	if until.After(l.paused[class]) {
+		l.paused[class] = until
+	}
+}
+
+// parseRetryAfter parses delay-seconds or HTTP-date of the Retry-After header
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
+}
+
+// operationClass classifies the request by method and path
+func operationClass(method, rawURL string) OperationClass {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return OperationWrite
+	}
+	switch {
+	case strings.HasSuffix(u.Path, HeartbeatPath):
+		return OperationHeartbeat
+	case method == http.MethodGet:
+		return OperationRead
+	case strings.HasSuffix(u.Path, BatchInstancePath) && u.Query().Get("type") == "query":
+		return OperationRead
+	}
+	return OperationWrite
+}
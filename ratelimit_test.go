@@ -0,0 +1,111 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestOptions_RateLimit(t *testing.T) {
+	var requests int32
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 && request.Method == http.MethodGet {
+			writer.Header().Set("Retry-After", "1")
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		writer.Write([]byte(`{"services":[]}`))
+	}))
+	defer scServer.Close()
+
+	t.Run("fail fast should return typed error", func(t *testing.T) {
+		c, err := sc.NewClient(sc.Options{
+			Endpoints: []string{scServer.Listener.Addr().String()},
+			RateLimit: &sc.RateLimitOptions{
+				Read:     sc.RateLimit{QPS: 1, Burst: 1},
+				FailFast: true,
+			},
+		})
+		assert.NoError(t, err)
+		atomic.StoreInt32(&requests, 1)
+		_, err = c.GetAllMicroServices()
+		assert.NoError(t, err)
+		_, err = c.GetAllMicroServices()
+		rle, ok := err.(*sc.RateLimitError)
+		assert.True(t, ok)
+		assert.Equal(t, sc.OperationRead, rle.Class)
+		assert.Greater(t, rle.RetryAfter, time.Duration(0))
+
+		// write budget is not consumed by reads
+		_, err = c.UpdateMicroServiceInstanceStatus("sid", "iid", sc.MSInstanceUP)
+		_, limited := err.(*sc.RateLimitError)
+		assert.False(t, limited)
+	})
+	t.Run("429 should pause the operation class", func(t *testing.T) {
+		c, err := sc.NewClient(sc.Options{
+			Endpoints: []string{scServer.Listener.Addr().String()},
+			RateLimit: &sc.RateLimitOptions{FailFast: true},
+		})
+		assert.NoError(t, err)
+		atomic.StoreInt32(&requests, 0)
+		_, err = c.GetAllMicroServices()
+		assert.Error(t, err)
+		_, err = c.GetAllMicroServices()
+		rle, ok := err.(*sc.RateLimitError)
+		assert.True(t, ok)
+		assert.InDelta(t, float64(time.Second), float64(rle.RetryAfter), float64(100*time.Millisecond))
+	})
+	t.Run("waiting limiter should delay the request", func(t *testing.T) {
+		c, err := sc.NewClient(sc.Options{
+			Endpoints: []string{scServer.Listener.Addr().String()},
+			RateLimit: &sc.RateLimitOptions{
+				Read: sc.RateLimit{QPS: 10, Burst: 1},
+			},
+		})
+		assert.NoError(t, err)
+		atomic.StoreInt32(&requests, 1)
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err = c.GetAllMicroServices()
+			assert.NoError(t, err)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	})
+}
+
+func TestOptions_RateLimitWithHedging(t *testing.T) {
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		writer.Write([]byte(`{"instances":[{"instanceId":"i1"}]}`))
+	})
+	server1 := httptest.NewServer(handler)
+	defer server1.Close()
+	server2 := httptest.NewServer(handler)
+	defer server2.Close()
+
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{server1.Listener.Addr().String(), server2.Listener.Addr().String()},
+		Hedging:   &sc.HedgingOptions{Delay: 10 * time.Millisecond},
+		RateLimit: &sc.RateLimitOptions{
+			Read: sc.RateLimit{QPS: 2, Burst: 1},
+		},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	// the hedged request waits for the next token and is canceled when the first one answers
+	_, err = c.FindInstances("", "default", "provider", sc.WithoutRevision())
+	assert.NoError(t, err)
+	// the canceled request returns its token in background, so the next one only waits for one token
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	_, err = c.FindInstances("", "default", "provider", sc.WithoutRevision())
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 700*time.Millisecond)
+}
@@ -0,0 +1,132 @@
+package sc
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Define the defaults of the reconnection
+const (
+	DefaultReconnectInitialInterval = 1 * time.Second
+	DefaultReconnectMaxInterval     = 30 * time.Second
+	DefaultReconnectMultiplier      = 1.5
+	DefaultMaxConcurrentReconnects  = 4
+)
+
+// DefaultReconnectScheduler is shared by all clients which have no ReconnectScheduler in Options,
+// so the concurrency cap applies to the whole process
+var DefaultReconnectScheduler = NewReconnectScheduler(BackoffPolicy{}, 0)
+
+// BackoffPolicy decides the delay between reconnection attempts,
+// the delay is a random duration in [0, min(MaxInterval, InitialInterval*Multiplier^attempt)) (full jitter)
+type BackoffPolicy struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	// MaxElapsedTime stops the reconnection after it, zero means never stop
+	MaxElapsedTime time.Duration
+}
+
+func (p BackoffPolicy) withDefaults() BackoffPolicy {
+	if p.InitialInterval <= 0 {
+		p.InitialInterval = DefaultReconnectInitialInterval
+	}
+	if p.MaxInterval <= 0 {
+		p.MaxInterval = DefaultReconnectMaxInterval
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultReconnectMultiplier
+	}
+	return p
+}
+
+// Delay returns the jittered delay before the attempt, attempt starts from 0
+func (p BackoffPolicy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+	ceil := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(attempt))
+	if ceil > float64(p.MaxInterval) || math.IsInf(ceil, 0) || math.IsNaN(ceil) {
+		ceil = float64(p.MaxInterval)
+	}
+	if ceil < 1 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceil)))
+}
+
+// ReconnectScheduler retries the websocket reconnections with jittered backoff,
+// and limits how many dials run at the same time.
+// it is safe to share a scheduler between clients
+type ReconnectScheduler struct {
+	policy BackoffPolicy
+	slots  chan struct{}
+}
+
+// NewReconnectScheduler creates a scheduler, maxConcurrent <= 0 means DefaultMaxConcurrentReconnects
+func NewReconnectScheduler(policy BackoffPolicy, maxConcurrent int) *ReconnectScheduler {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentReconnects
+	}
+	return &ReconnectScheduler{
+		policy: policy.withDefaults(),
+		slots:  make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Retry waits a jittered delay and runs op until it succeeds, the context is done or MaxElapsedTime is exceeded.
+// op is called with the attempt number starting from 1 and it occupies one dial slot while running.
+// notify, if not nil, is called after each failure with the delay of the next attempt
+func (s *ReconnectScheduler) Retry(ctx context.Context, op func(attempt int) error,
+	notify func(err error, next time.Duration)) error {
+	start := time.Now()
+	var err error
+	for attempt := 0; ; attempt++ {
+		delay := s.policy.Delay(attempt)
+		if s.policy.MaxElapsedTime > 0 && time.Since(start)+delay > s.policy.MaxElapsedTime {
+			if err == nil {
+				err = context.DeadlineExceeded
+			}
+			return err
+		}
+		if attempt > 0 && notify != nil {
+			notify(err, delay)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s.slots <- struct{}{}:
+		}
+		err = op(attempt + 1)
+		<-s.slots
+		if err == nil {
+			return nil
+		}
+	}
+}
+
+// reconnectAddress returns the address for the next attempt,
+// the addresses which failed in this round of reconnection are avoided if there is any other choice
+func (c *Client) reconnectAddress(failed map[string]bool) string {
+	addr := c.GetAddress()
+	if !failed[addr] {
+		return addr
+	}
+	for _, candidate := range c.candidateAddresses() {
+		if !failed[candidate] {
+			return candidate
+		}
+	}
+	// every address failed, start a new round
+	for k := range failed {
+		delete(failed, k)
+	}
+	return addr
+}
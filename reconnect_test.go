@@ -0,0 +1,85 @@
+package sc_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestBackoffPolicy_Delay(t *testing.T) {
+	p := sc.BackoffPolicy{
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     100 * time.Millisecond,
+		Multiplier:      2,
+	}
+	for attempt := 0; attempt < 20; attempt++ {
+		d := p.Delay(attempt)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.Less(t, d, 100*time.Millisecond)
+		if attempt == 0 {
+			assert.Less(t, d, 10*time.Millisecond)
+		}
+	}
+}
+
+func TestReconnectScheduler_Retry(t *testing.T) {
+	policy := sc.BackoffPolicy{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+	}
+	t.Run("should retry until success", func(t *testing.T) {
+		s := sc.NewReconnectScheduler(policy, 1)
+		var notified int
+		err := s.Retry(context.Background(), func(attempt int) error {
+			if attempt < 3 {
+				return errors.New("dial failed")
+			}
+			return nil
+		}, func(err error, next time.Duration) {
+			notified++
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, notified)
+	})
+	t.Run("should limit concurrent dials", func(t *testing.T) {
+		s := sc.NewReconnectScheduler(policy, 2)
+		var running, peak int32
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = s.Retry(context.Background(), func(int) error {
+					n := atomic.AddInt32(&running, 1)
+					for {
+						p := atomic.LoadInt32(&peak)
+						if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					atomic.AddInt32(&running, -1)
+					return nil
+				}, nil)
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, atomic.LoadInt32(&peak), int32(2))
+	})
+	t.Run("should stop when context is done", func(t *testing.T) {
+		s := sc.NewReconnectScheduler(policy, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := s.Retry(ctx, func(int) error {
+			return errors.New("dial failed")
+		}, nil)
+		assert.Equal(t, context.DeadlineExceeded, err)
+	})
+}
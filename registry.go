@@ -0,0 +1,76 @@
+package sc
+
+import (
+	"github.com/go-chassis/cari/discovery"
+)
+
+// Registrar registers and maintains the services and instances
+type Registrar interface {
+	RegisterService(microService *discovery.MicroService) (string, error)
+	UnregisterMicroService(microServiceID string) (bool, error)
+	DeleteService(microServiceID string, force bool) error
+	DeleteServices(microServiceIDs []string, force bool) (map[string]error, error)
+	UpdateMicroServiceProperties(microServiceID string, microService *discovery.MicroService) (bool, error)
+	PatchServiceProperties(microServiceID string, patch PropertiesPatch) (map[string]string, error)
+	AddServiceTags(microServiceID string, tags map[string]string) error
+	AddDependencies(dependencies []*discovery.ConsumerDependency) error
+	RegisterMicroServiceInstance(microServiceInstance *discovery.MicroServiceInstance) (string, error)
+	UnregisterMicroServiceInstance(microServiceID, microServiceInstanceID string) (bool, error)
+	UpdateMicroServiceInstanceStatus(microServiceID, microServiceInstanceID, status string) (bool, error)
+	UpdateMicroServiceInstanceProperties(microServiceID, microServiceInstanceID string,
+		microServiceInstance *discovery.MicroServiceInstance) (bool, error)
+	PatchInstanceProperties(microServiceID, microServiceInstanceID string,
+		patch PropertiesPatch) (map[string]string, error)
+	Heartbeat(microServiceID, microServiceInstanceID string) (bool, error)
+	WSHeartbeat(microServiceID, microServiceInstanceID string, callback func()) error
+}
+
+// Discoverer queries the services and instances
+type Discoverer interface {
+	GetMicroServiceID(appID, microServiceName, version, env string, opts ...CallOption) (string, error)
+	GetMicroService(microServiceID string, opts ...CallOption) (*discovery.MicroService, error)
+	GetAllMicroServices(opts ...CallOption) ([]*discovery.MicroService, error)
+	GetAllApplications(opts ...CallOption) ([]string, error)
+	GetProviders(consumer string, opts ...CallOption) (*MicroServiceProvideResponse, error)
+	GetAllResources(resource string, opts ...CallOption) ([]*discovery.ServiceDetail, error)
+	GetServiceDetails(opts ...CallOption) ([]*discovery.ServiceDetail, error)
+	EachService(fn func(*discovery.MicroService) error, opts ...CallOption) error
+	GetServiceDetail(microServiceID string, opts ...CallOption) (*discovery.ServiceDetail, error)
+	GetStatistics(opts ...CallOption) (*discovery.Statistics, error)
+	EachServiceDetail(fn func(*discovery.ServiceDetail) error, opts ...CallOption) error
+	FindMicroServiceInstances(consumerID, appID, microServiceName, versionRule string,
+		opts ...CallOption) ([]*discovery.MicroServiceInstance, error)
+	FindInstances(consumerID, appID, microServiceName string, opts ...CallOption) (*FindMicroServiceInstancesResult, error)
+	BatchFindInstances(consumerID string, keys []*discovery.FindService,
+		opts ...CallOption) (*discovery.BatchFindInstancesResponse, error)
+	GetMicroServiceInstances(consumerID, providerID string, opts ...CallOption) ([]*discovery.MicroServiceInstance, error)
+	GetInstance(consumerID, providerID, instanceID string, opts ...CallOption) (*discovery.MicroServiceInstance, error)
+	InstanceExists(microServiceID, microServiceInstanceID string, opts ...CallOption) (bool, error)
+}
+
+// Watcher watches the instance changes of the providers
+type Watcher interface {
+	WatchMicroService(microServiceID string, callback func(*MicroServiceInstanceChangedEvent)) error
+	WatchMicroServiceWithExtraHandle(microServiceID string, callback func(e *MicroServiceInstanceChangedEvent),
+		extraHandle func(action string, opts ...CallOption)) error
+	DisconnectMicroServiceWatching(microServiceID string)
+}
+
+// SchemaStore stores the schemas of the services
+type SchemaStore interface {
+	AddSchemas(microServiceID, schemaName, schemaInfo string) error
+	GetSchema(microServiceID, schemaName string, opts ...CallOption) ([]byte, error)
+	SchemaExists(microServiceID, schemaID string, opts ...CallOption) (string, error)
+}
+
+// Registry is everything a micro service needs from service center, Client implements it.
+// depend on the smaller role interfaces if you only need some of them
+type Registry interface {
+	Registrar
+	Discoverer
+	Watcher
+	SchemaStore
+	Close() error
+}
+
+var _ Registry = (*Client)(nil)
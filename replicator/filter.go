@@ -0,0 +1,35 @@
+package replicator
+
+import "github.com/go-chassis/cari/discovery"
+
+// Filter selects the services to replicate by app and environment,
+// an empty include list means all, the exclude lists win over the include lists
+type Filter struct {
+	IncludeApps         []string
+	ExcludeApps         []string
+	IncludeEnvironments []string
+	ExcludeEnvironments []string
+}
+
+// Match returns true if the service should be replicated
+func (f Filter) Match(s *discovery.MicroService) bool {
+	if contains(f.ExcludeApps, s.AppId) || contains(f.ExcludeEnvironments, s.Environment) {
+		return false
+	}
+	if len(f.IncludeApps) > 0 && !contains(f.IncludeApps, s.AppId) {
+		return false
+	}
+	if len(f.IncludeEnvironments) > 0 && !contains(f.IncludeEnvironments, s.Environment) {
+		return false
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,632 @@
+// Package replicator mirrors the services and instances of a source service center into a target one,
+// it is used to migrate the micro services between clusters
+package replicator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+
+	"github.com/go-chassis/sc-client"
+)
+
+// Define the defaults of the replicator
+const (
+	DefaultReconcileInterval = 30 * time.Second
+	DefaultHeartbeatInterval = sc.DefaultLeaseRenewalInterval * time.Second
+	// DefaultWatcherName is the name of the consumers registered in the source to watch the replicated services
+	DefaultWatcherName = "sc-replicator"
+)
+
+const (
+	watcherAppID   = "default"
+	watcherVersion = "1.0.0"
+)
+
+// ErrStarted means Start is called while the replicator is running
+var ErrStarted = errors.New("replicator is already started")
+
+// State is the phase of the replicator
+type State string
+
+// Define the states of the replicator
+const (
+	StateIdle    State = "idle"
+	StateCopying State = "copying"
+	StateRunning State = "running"
+	StateStopped State = "stopped"
+)
+
+// Options is the options of the replicator
+type Options struct {
+	Source *sc.Client
+	Target *sc.Client
+	Filter Filter
+	// ReconcileInterval is how often the instances are compared with the source by revision
+	ReconcileInterval time.Duration
+	// HeartbeatInterval is how often the replicated instances are renewed in the target,
+	// they expire like any other instance without heartbeats
+	HeartbeatInterval time.Duration
+	// DisableWatch relies on the reconciliation only, nothing is registered in the source
+	DisableWatch bool
+	// WatcherName is the name of the consumers registered in the source to receive the instance events,
+	// the source only notifies a consumer about the providers it depends on
+	WatcherName string
+	Logger      sc.Logger
+}
+
+// Status shows the progress of the replication
+type Status struct {
+	State State
+	// Services and Instances are the numbers of the replicated services and instances
+	Services  int
+	Instances int
+	StartedAt time.Time
+	// LastReconcileAt is the time the last reconciliation finished
+	LastReconcileAt time.Time
+	// LastEventAt is the time the last watch event was applied
+	LastEventAt   time.Time
+	PendingEvents int
+	// Lag is how long the oldest pending event has been waiting, zero if there is none
+	Lag       time.Duration
+	Errors    int
+	LastError string
+}
+
+// service is a replicated service, the instances are keyed by the source instance id
+type service struct {
+	source    *discovery.MicroService
+	targetID  string
+	created   bool
+	instances map[string]*discovery.MicroServiceInstance
+}
+
+// watcher is a consumer registered in the source, it depends on the replicated services of its environment,
+// so one watch connection receives the instance events of all of them
+type watcher struct {
+	serviceID string
+	key       *discovery.MicroServiceKey
+}
+
+type event struct {
+	e          *sc.MicroServiceInstanceChangedEvent
+	receivedAt time.Time
+}
+
+// Replicator copies the filtered services and instances from the source to the target,
+// then keeps the target updated by the watch events of the source and the periodic reconciliation.
+// the services and instances keep their ids in the target,
+// only the services and instances created by the replicator are deleted from the target.
+// unless the watch is disabled, one watcher service per environment is registered in the source while it runs
+type Replicator struct {
+	opts Options
+	log  sc.Logger
+
+	// services, revisions and watchers are only accessed by the goroutine which owns the replication
+	services  map[string]*service
+	revisions map[string]string
+	watchers  map[string]*watcher
+
+	mutex  sync.Mutex
+	status Status
+	queue  []event
+	signal chan struct{}
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a replicator
+func New(opts Options) (*Replicator, error) {
+	if opts.Source == nil || opts.Target == nil {
+		return nil, fmt.Errorf("source and target are required")
+	}
+	if opts.ReconcileInterval <= 0 {
+		opts.ReconcileInterval = DefaultReconcileInterval
+	}
+	if opts.HeartbeatInterval <= 0 {
+		opts.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if opts.WatcherName == "" {
+		opts.WatcherName = DefaultWatcherName
+	}
+	if opts.Logger == nil {
+		opts.Logger = sc.NewOpenlogLogger()
+	}
+	return &Replicator{
+		opts:      opts,
+		log:       opts.Logger,
+		services:  make(map[string]*service),
+		revisions: make(map[string]string),
+		watchers:  make(map[string]*watcher),
+		status:    Status{State: StateIdle},
+		signal:    make(chan struct{}, 1),
+	}, nil
+}
+
+// Start performs the initial copy and keeps replicating in background until ctx is done or Stop is called.
+// it returns the error of the initial copy, a stopped replicator can be started again
+func (r *Replicator) Start(ctx context.Context) error {
+	r.mutex.Lock()
+	if r.status.State != StateIdle && r.status.State != StateStopped {
+		r.mutex.Unlock()
+		return ErrStarted
+	}
+	r.status.State = StateCopying
+	r.status.StartedAt = time.Now()
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	r.mutex.Unlock()
+
+	if !r.opts.DisableWatch {
+		// the services replicated by the former run are not added again, so their watches are restored here
+		for _, s := range r.services {
+			r.watch(s.source)
+		}
+	}
+	if err := r.reconcile(ctx); err != nil {
+		r.cancel()
+		r.unwatch()
+		r.setState(StateStopped)
+		close(r.done)
+		return err
+	}
+	r.log.Info("initial replication finished", "services", len(r.services), "instances", r.countInstances())
+	r.setState(StateRunning)
+	go r.run(ctx)
+	return nil
+}
+
+// Stop stops the replication and unregisters the watchers, the replicated resources are kept in the target
+func (r *Replicator) Stop() {
+	r.mutex.Lock()
+	cancel, done := r.cancel, r.done
+	r.mutex.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Status returns the current status
+func (r *Replicator) Status() Status {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s := r.status
+	s.PendingEvents = len(r.queue)
+	if len(r.queue) > 0 {
+		s.Lag = time.Since(r.queue[0].receivedAt)
+	}
+	return s
+}
+
+func (r *Replicator) run(ctx context.Context) {
+	defer close(r.done)
+	reconcile := time.NewTicker(r.opts.ReconcileInterval)
+	defer reconcile.Stop()
+	heartbeat := time.NewTicker(r.opts.HeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			r.unwatch()
+			r.setState(StateStopped)
+			return
+		case <-r.signal:
+			r.applyEvents()
+		case <-reconcile.C:
+			if err := r.reconcile(ctx); err != nil {
+				r.log.Warn("reconcile failed", "error", err)
+			}
+		case <-heartbeat.C:
+			r.heartbeat()
+		}
+	}
+}
+
+// reconcile lists the source services, replicates the new ones, removes the deleted ones,
+// and replicates the instances of the services whose revision changed
+func (r *Replicator) reconcile(ctx context.Context) error {
+	services, err := r.opts.Source.GetAllMicroServices()
+	if err != nil {
+		r.fail(err)
+		return err
+	}
+	current := make(map[string]bool)
+	for _, s := range services {
+		if !r.opts.Filter.Match(s) || r.isWatcher(s) {
+			continue
+		}
+		current[s.ServiceId] = true
+		if _, ok := r.services[s.ServiceId]; ok {
+			continue
+		}
+		if err = r.addService(s); err != nil {
+			r.fail(err)
+		}
+	}
+	for id, s := range r.services {
+		if !current[id] {
+			r.removeService(id, s)
+		}
+	}
+	for _, group := range r.groups() {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = r.reconcileGroup(group); err != nil {
+			r.fail(err)
+		}
+	}
+	r.mutex.Lock()
+	r.status.LastReconcileAt = time.Now()
+	r.mutex.Unlock()
+	r.updateCounts()
+	return nil
+}
+
+func (r *Replicator) addService(s *discovery.MicroService) error {
+	targetID, err := r.opts.Target.GetMicroServiceID(s.AppId, s.ServiceName, s.Version, s.Environment)
+	if err != nil {
+		return err
+	}
+	created := false
+	if targetID == "" {
+		copied := *s
+		if targetID, err = r.opts.Target.RegisterService(&copied); err != nil {
+			return err
+		}
+		created = true
+	}
+	r.services[s.ServiceId] = &service{
+		source:    s,
+		targetID:  targetID,
+		created:   created,
+		instances: make(map[string]*discovery.MicroServiceInstance),
+	}
+	r.log.Info("service replicated", "service", serviceKey(s), "targetID", targetID, "created", created)
+	if !r.opts.DisableWatch {
+		r.watch(s)
+	}
+	return nil
+}
+
+func (r *Replicator) removeService(id string, s *service) {
+	for instanceID := range s.instances {
+		r.removeInstance(s, instanceID)
+	}
+	if s.created {
+		if _, err := r.opts.Target.UnregisterMicroService(s.targetID); err != nil {
+			r.fail(err)
+		}
+	}
+	delete(r.services, id)
+	r.log.Info("service removed", "service", serviceKey(s.source), "targetID", s.targetID)
+}
+
+// group is the services sharing one instance query, the query matches all versions of the service name
+type group struct {
+	key      string
+	env      string
+	appID    string
+	name     string
+	services []*service
+}
+
+func (r *Replicator) groups() []*group {
+	groups := make(map[string]*group)
+	for _, s := range r.services {
+		key := s.source.Environment + "/" + s.source.AppId + "/" + s.source.ServiceName
+		g, ok := groups[key]
+		if !ok {
+			g = &group{key: key, env: s.source.Environment, appID: s.source.AppId, name: s.source.ServiceName}
+			groups[key] = g
+		}
+		g.services = append(g.services, s)
+	}
+	list := make([]*group, 0, len(groups))
+	for _, g := range groups {
+		list = append(list, g)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].key < list[j].key
+	})
+	return list
+}
+
+// findInstances queries the instances of the group.
+// the environment of FindInstances is the one of the consumer, and querying with a provider as the consumer
+// makes the source record a dependency on itself, so the services out of the default environment
+// are queried one by one by their ids, without revision
+func (r *Replicator) findInstances(g *group) (*sc.FindMicroServiceInstancesResult, error) {
+	if g.env == "" {
+		return r.opts.Source.FindInstances("", g.appID, g.name, sc.WithRevision(r.revisions[g.key]))
+	}
+	rst := &sc.FindMicroServiceInstancesResult{}
+	for _, s := range g.services {
+		instances, err := r.opts.Source.GetMicroServiceInstances("", s.source.ServiceId)
+		if err != nil {
+			return nil, err
+		}
+		rst.Instances = append(rst.Instances, instances...)
+	}
+	return rst, nil
+}
+
+func (r *Replicator) reconcileGroup(g *group) error {
+	rst, err := r.findInstances(g)
+	if err == sc.ErrNotModified {
+		return nil
+	}
+	if err == sc.ErrMicroServiceNotExists {
+		rst, err = &sc.FindMicroServiceInstancesResult{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if rst.Stale {
+		return fmt.Errorf("instances of %s are stale", g.key)
+	}
+	desired := make(map[string]map[string]*discovery.MicroServiceInstance)
+	for _, i := range rst.Instances {
+		if desired[i.ServiceId] == nil {
+			desired[i.ServiceId] = make(map[string]*discovery.MicroServiceInstance)
+		}
+		desired[i.ServiceId][i.InstanceId] = i
+	}
+	ok := true
+	for _, s := range g.services {
+		instances := desired[s.source.ServiceId]
+		for _, i := range instances {
+			if err := r.putInstance(s, i); err != nil {
+				r.fail(err)
+				ok = false
+			}
+		}
+		for id := range s.instances {
+			if _, exists := instances[id]; !exists {
+				r.removeInstance(s, id)
+			}
+		}
+	}
+	if ok {
+		r.revisions[g.key] = rst.Revision
+	}
+	return nil
+}
+
+// putInstance registers the instance in the target if it is new or changed
+func (r *Replicator) putInstance(s *service, i *discovery.MicroServiceInstance) error {
+	old, exists := s.instances[i.InstanceId]
+	if exists && sameInstance(old, i) {
+		return nil
+	}
+	if exists && old.Status != i.Status && sameInstance(old, withStatus(i, old.Status)) {
+		if _, err := r.opts.Target.UpdateMicroServiceInstanceStatus(s.targetID, old.InstanceId, i.Status); err != nil {
+			return err
+		}
+		old.Status = i.Status
+		return nil
+	}
+	copied := *i
+	copied.ServiceId = s.targetID
+	id, err := r.opts.Target.RegisterMicroServiceInstance(&copied)
+	if err != nil {
+		return err
+	}
+	copied.InstanceId = id
+	s.instances[i.InstanceId] = &copied
+	return nil
+}
+
+func (r *Replicator) removeInstance(s *service, sourceID string) {
+	i, ok := s.instances[sourceID]
+	if !ok {
+		return
+	}
+	if _, err := r.opts.Target.UnregisterMicroServiceInstance(s.targetID, i.InstanceId); err != nil {
+		r.fail(err)
+	}
+	delete(s.instances, sourceID)
+}
+
+// heartbeat renews the replicated instances, the lost ones are registered again in the next reconciliation
+func (r *Replicator) heartbeat() {
+	for _, s := range r.services {
+		for sourceID, i := range s.instances {
+			if _, err := r.opts.Target.Heartbeat(s.targetID, i.InstanceId); err != nil {
+				r.fail(err)
+				delete(s.instances, sourceID)
+				r.forget(s)
+			}
+		}
+	}
+}
+
+// forget resets the revision of the service, so the next reconciliation compares all its instances
+func (r *Replicator) forget(s *service) {
+	delete(r.revisions, s.source.Environment+"/"+s.source.AppId+"/"+s.source.ServiceName)
+}
+
+// watch makes the watcher of the environment depend on the service
+func (r *Replicator) watch(s *discovery.MicroService) {
+	w, err := r.watcher(s.Environment)
+	if err == nil {
+		err = r.opts.Source.AddDependencies([]*discovery.ConsumerDependency{{
+			Consumer: w.key,
+			Providers: []*discovery.MicroServiceKey{{
+				Environment: s.Environment,
+				AppId:       s.AppId,
+				ServiceName: s.ServiceName,
+				Version:     s.Version,
+			}},
+		}})
+	}
+	if err != nil {
+		// the reconciliation still replicates the service
+		r.log.Warn("watch source service failed", "service", serviceKey(s), "error", err)
+	}
+}
+
+// watcher returns the watcher of the environment, it is registered and watched at the first use,
+// the dependencies only match the providers in the same environment, so there is one watcher per environment
+func (r *Replicator) watcher(env string) (*watcher, error) {
+	if w, ok := r.watchers[env]; ok {
+		return w, nil
+	}
+	key := &discovery.MicroServiceKey{
+		Environment: env,
+		AppId:       watcherAppID,
+		ServiceName: r.opts.WatcherName,
+		Version:     watcherVersion,
+	}
+	id, err := r.opts.Source.GetMicroServiceID(key.AppId, key.ServiceName, key.Version, key.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		id, err = r.opts.Source.RegisterService(&discovery.MicroService{
+			Environment: key.Environment,
+			AppId:       key.AppId,
+			ServiceName: key.ServiceName,
+			Version:     key.Version,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = r.opts.Source.WatchMicroService(id, r.enqueue); err != nil {
+		return nil, err
+	}
+	w := &watcher{serviceID: id, key: key}
+	r.watchers[env] = w
+	r.log.Info("source watched", "environment", env, "watcherID", id)
+	return w, nil
+}
+
+func (r *Replicator) isWatcher(s *discovery.MicroService) bool {
+	return s.AppId == watcherAppID && s.ServiceName == r.opts.WatcherName
+}
+
+func (r *Replicator) enqueue(e *sc.MicroServiceInstanceChangedEvent) {
+	r.mutex.Lock()
+	r.queue = append(r.queue, event{e: e, receivedAt: time.Now()})
+	r.mutex.Unlock()
+	select {
+	case r.signal <- struct{}{}:
+	default:
+	}
+}
+
+// unwatch disconnects and unregisters the watchers, their dependencies are removed by the source
+func (r *Replicator) unwatch() {
+	for env, w := range r.watchers {
+		r.opts.Source.DisconnectMicroServiceWatching(w.serviceID)
+		if _, err := r.opts.Source.UnregisterMicroService(w.serviceID); err != nil {
+			r.log.Warn("unregister watcher failed", "watcherID", w.serviceID, "error", err)
+		}
+		delete(r.watchers, env)
+	}
+}
+
+// applyEvents applies the queued watch events in order
+func (r *Replicator) applyEvents() {
+	for {
+		r.mutex.Lock()
+		if len(r.queue) == 0 {
+			r.mutex.Unlock()
+			r.updateCounts()
+			return
+		}
+		e := r.queue[0]
+		r.mutex.Unlock()
+
+		r.applyEvent(e.e)
+
+		r.mutex.Lock()
+		r.queue = r.queue[1:]
+		r.status.LastEventAt = time.Now()
+		r.mutex.Unlock()
+	}
+}
+
+func (r *Replicator) applyEvent(e *sc.MicroServiceInstanceChangedEvent) {
+	if e.Instance == nil {
+		return
+	}
+	s, ok := r.services[e.Instance.ServiceId]
+	if !ok {
+		// the service is removed after its dependency is added
+		return
+	}
+	switch strings.ToUpper(e.Action) {
+	case sc.EventCreate, sc.EventUpdate:
+		if err := r.putInstance(s, e.Instance); err != nil {
+			r.fail(err)
+			r.forget(s)
+		}
+	case sc.EventDelete, "EXPIRE":
+		r.removeInstance(s, e.Instance.InstanceId)
+	}
+}
+
+func (r *Replicator) fail(err error) {
+	r.log.Error("replicate failed", "error", err)
+	r.mutex.Lock()
+	r.status.Errors++
+	r.status.LastError = err.Error()
+	r.mutex.Unlock()
+}
+
+func (r *Replicator) setState(s State) {
+	r.mutex.Lock()
+	r.status.State = s
+	r.mutex.Unlock()
+}
+
+func (r *Replicator) countInstances() int {
+	n := 0
+	for _, s := range r.services {
+		n += len(s.instances)
+	}
+	return n
+}
+
+func (r *Replicator) updateCounts() {
+	services, instances := len(r.services), r.countInstances()
+	r.mutex.Lock()
+	r.status.Services = services
+	r.status.Instances = instances
+	r.mutex.Unlock()
+}
+
+func sameInstance(a, b *discovery.MicroServiceInstance) bool {
+	return a.Status == b.Status && a.HostName == b.HostName && a.Version == b.Version &&
+		strings.Join(a.Endpoints, ",") == strings.Join(b.Endpoints, ",") &&
+		formatMap(a.Properties) == formatMap(b.Properties)
+}
+
+func withStatus(i *discovery.MicroServiceInstance, status string) *discovery.MicroServiceInstance {
+	copied := *i
+	copied.Status = status
+	return &copied
+}
+
+func formatMap(m map[string]string) string {
+	pairs := make([]string, 0, len(m))
+	for k, v := range m {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func serviceKey(s *discovery.MicroService) string {
+	return fmt.Sprintf("%s/%s/%s", s.AppId, s.ServiceName, s.Version)
+}
@@ -0,0 +1,358 @@
+package replicator_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+	"github.com/go-chassis/sc-client/replicator"
+)
+
+// fakeRegistry is an in-memory service center serving the APIs used by the replicator
+type fakeRegistry struct {
+	mutex     sync.Mutex
+	services  map[string]*discovery.MicroService
+	instances map[string]*discovery.MicroServiceInstance
+	revision  int
+	seq       int
+	failing   bool
+	consumers []string
+	// dependencies are the provider keys of the consumer keys, watches are the connections by service id
+	dependencies map[string][]string
+	watches      map[string]*websocket.Conn
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, *sc.Client) {
+	f := &fakeRegistry{
+		services:     make(map[string]*discovery.MicroService),
+		instances:    make(map[string]*discovery.MicroServiceInstance),
+		dependencies: make(map[string][]string),
+		watches:      make(map[string]*websocket.Conn),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}, LogLevel: sc.LevelSilent})
+	assert.NoError(t, err)
+	return f, c
+}
+
+func (f *fakeRegistry) addService(s *discovery.MicroService) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.services[s.ServiceId] = s
+	f.revision++
+}
+
+func (f *fakeRegistry) addInstance(i *discovery.MicroServiceInstance) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.instances[i.InstanceId] = i
+	f.revision++
+	f.notify(sc.EventCreate, i)
+}
+
+func (f *fakeRegistry) deleteInstance(id string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if i, ok := f.instances[id]; ok {
+		delete(f.instances, id)
+		f.notify(sc.EventDelete, i)
+	}
+	f.revision++
+}
+
+func keyOf(k *discovery.MicroServiceKey) string {
+	return k.Environment + "/" + k.AppId + "/" + k.ServiceName + "/" + k.Version
+}
+
+func serviceKeyOf(s *discovery.MicroService) string {
+	return s.Environment + "/" + s.AppId + "/" + s.ServiceName + "/" + s.Version
+}
+
+// notify sends the event to the watching consumers of the instance's service like service center does
+func (f *fakeRegistry) notify(action string, i *discovery.MicroServiceInstance) {
+	provider := f.services[i.ServiceId]
+	if provider == nil {
+		return
+	}
+	for id, conn := range f.watches {
+		consumer := f.services[id]
+		if consumer == nil {
+			continue
+		}
+		for _, p := range f.dependencies[serviceKeyOf(consumer)] {
+			if p == serviceKeyOf(provider) {
+				conn.WriteJSON(&sc.MicroServiceInstanceChangedEvent{Action: action, Instance: i})
+			}
+		}
+	}
+}
+
+func (f *fakeRegistry) snapshot() (map[string]string, map[string]string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	services := make(map[string]string)
+	for id, s := range f.services {
+		services[id] = s.ServiceName
+	}
+	instances := make(map[string]string)
+	for id, i := range f.instances {
+		instances[id] = i.Status
+	}
+	return services, instances
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v4/default/registry")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if consumer := r.Header.Get("X-ConsumerId"); consumer != "" {
+		f.consumers = append(f.consumers, consumer)
+	}
+	if f.failing {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && path == "/microservices":
+		services := []*discovery.MicroService{}
+		for _, s := range f.services {
+			services = append(services, s)
+		}
+		json.NewEncoder(w).Encode(&discovery.GetServicesResponse{Services: services})
+	case r.Method == http.MethodGet && path == "/existence":
+		q := r.URL.Query()
+		for _, s := range f.services {
+			if s.AppId == q.Get("appId") && s.ServiceName == q.Get("serviceName") && s.Version == q.Get("version") {
+				fmt.Fprintf(w, `{"serviceId":%q}`, s.ServiceId)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"errorCode":"400012"}`))
+	case r.Method == http.MethodPost && path == "/microservices":
+		req := &discovery.CreateServiceRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		if req.Service.ServiceId == "" {
+			f.seq++
+			req.Service.ServiceId = fmt.Sprintf("generated%d", f.seq)
+		}
+		f.services[req.Service.ServiceId] = req.Service
+		f.revision++
+		fmt.Fprintf(w, `{"serviceId":%q}`, req.Service.ServiceId)
+	case r.Method == http.MethodDelete && len(parts) == 2:
+		if s, ok := f.services[parts[1]]; ok {
+			delete(f.dependencies, serviceKeyOf(s))
+		}
+		delete(f.services, parts[1])
+		f.revision++
+	case r.Method == http.MethodPost && path == "/dependencies":
+		req := &discovery.AddDependenciesRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		for _, d := range req.Dependencies {
+			for _, p := range d.Providers {
+				f.dependencies[keyOf(d.Consumer)] = append(f.dependencies[keyOf(d.Consumer)], keyOf(p))
+			}
+		}
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "watcher":
+		upgrader := websocket.Upgrader{}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		f.watches[parts[1]] = conn
+	case r.Method == http.MethodGet && path == "/instances":
+		rev := fmt.Sprint(f.revision)
+		if r.URL.Query().Get("rev") == rev {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		instances := []*discovery.MicroServiceInstance{}
+		for _, i := range f.instances {
+			if s := f.services[i.ServiceId]; s != nil && s.ServiceName == r.URL.Query().Get("serviceName") {
+				instances = append(instances, i)
+			}
+		}
+		w.Header().Set(sc.HeaderRevision, rev)
+		json.NewEncoder(w).Encode(&discovery.GetInstancesResponse{Instances: instances})
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "instances":
+		instances := []*discovery.MicroServiceInstance{}
+		for _, i := range f.instances {
+			if i.ServiceId == parts[1] {
+				instances = append(instances, i)
+			}
+		}
+		json.NewEncoder(w).Encode(&discovery.GetInstancesResponse{Instances: instances})
+	case r.Method == http.MethodPost && len(parts) == 3:
+		req := &discovery.RegisterInstanceRequest{}
+		json.NewDecoder(r.Body).Decode(req)
+		f.instances[req.Instance.InstanceId] = req.Instance
+		f.revision++
+		fmt.Fprintf(w, `{"instanceId":%q}`, req.Instance.InstanceId)
+	case r.Method == http.MethodDelete && len(parts) == 4:
+		delete(f.instances, parts[3])
+		f.revision++
+	case r.Method == http.MethodPut && len(parts) == 5 && parts[4] == "heartbeat":
+		if _, ok := f.instances[parts[3]]; !ok {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	case r.Method == http.MethodPut && len(parts) == 5 && parts[4] == "status":
+		f.instances[parts[3]].Status = r.URL.Query().Get("value")
+		f.revision++
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReplicator(t *testing.T) {
+	source, sourceClient := newFakeRegistry(t)
+	target, targetClient := newFakeRegistry(t)
+	source.addService(&discovery.MicroService{ServiceId: "s1", AppId: "app", ServiceName: "s1", Version: "1.0.0"})
+	source.addService(&discovery.MicroService{ServiceId: "s2", AppId: "other", ServiceName: "s2", Version: "1.0.0"})
+	source.addInstance(&discovery.MicroServiceInstance{InstanceId: "i1", ServiceId: "s1", Status: "UP",
+		Endpoints: []string{"rest://1.1.1.1:80"}})
+
+	r, err := replicator.New(replicator.Options{
+		Source:            sourceClient,
+		Target:            targetClient,
+		Filter:            replicator.Filter{ExcludeApps: []string{"other"}},
+		ReconcileInterval: 20 * time.Millisecond,
+		HeartbeatInterval: time.Hour,
+		DisableWatch:      true,
+		Logger:            sc.NewOpenlogLogger(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, replicator.StateIdle, r.Status().State)
+	assert.NoError(t, r.Start(context.Background()))
+	assert.Equal(t, replicator.ErrStarted, r.Start(context.Background()))
+
+	services, instances := target.snapshot()
+	assert.Equal(t, map[string]string{"s1": "s1"}, services)
+	assert.Equal(t, map[string]string{"i1": "UP"}, instances)
+	status := r.Status()
+	assert.Equal(t, replicator.StateRunning, status.State)
+	assert.Equal(t, 1, status.Services)
+	assert.Equal(t, 1, status.Instances)
+
+	t.Run("reconcile should replicate the changes", func(t *testing.T) {
+		source.deleteInstance("i1")
+		source.addInstance(&discovery.MicroServiceInstance{InstanceId: "i2", ServiceId: "s1", Status: "UP",
+			Endpoints: []string{"rest://2.2.2.2:80"}})
+		assert.Eventually(t, func() bool {
+			_, instances := target.snapshot()
+			return len(instances) == 1 && instances["i2"] == "UP"
+		}, 3*time.Second, 10*time.Millisecond)
+	})
+	t.Run("status change should not register the instance again", func(t *testing.T) {
+		source.addInstance(&discovery.MicroServiceInstance{InstanceId: "i2", ServiceId: "s1", Status: "DOWN",
+			Endpoints: []string{"rest://2.2.2.2:80"}})
+		assert.Eventually(t, func() bool {
+			_, instances := target.snapshot()
+			return instances["i2"] == "DOWN"
+		}, 3*time.Second, 10*time.Millisecond)
+	})
+
+	r.Stop()
+	assert.Equal(t, replicator.StateStopped, r.Status().State)
+	assert.Zero(t, r.Status().Errors, r.Status().LastError)
+}
+
+func TestReplicator_Restart(t *testing.T) {
+	source, sourceClient := newFakeRegistry(t)
+	target, targetClient := newFakeRegistry(t)
+	source.addService(&discovery.MicroService{ServiceId: "s1", AppId: "app", ServiceName: "s1", Version: "1.0.0",
+		Environment: "development"})
+	source.addInstance(&discovery.MicroServiceInstance{InstanceId: "i1", ServiceId: "s1", Status: "UP",
+		Endpoints: []string{"rest://1.1.1.1:80"}})
+
+	r, err := replicator.New(replicator.Options{
+		Source:            sourceClient,
+		Target:            targetClient,
+		ReconcileInterval: time.Hour,
+		HeartbeatInterval: time.Hour,
+		DisableWatch:      true,
+		Logger:            sc.NewOpenlogLogger(),
+	})
+	assert.NoError(t, err)
+
+	source.mutex.Lock()
+	source.failing = true
+	source.mutex.Unlock()
+	assert.Error(t, r.Start(context.Background()))
+	assert.Equal(t, replicator.StateStopped, r.Status().State)
+
+	source.mutex.Lock()
+	source.failing = false
+	source.mutex.Unlock()
+	assert.NoError(t, r.Start(context.Background()))
+	defer r.Stop()
+	services, instances := target.snapshot()
+	assert.Equal(t, map[string]string{"s1": "s1"}, services)
+	assert.Equal(t, map[string]string{"i1": "UP"}, instances)
+
+	source.mutex.Lock()
+	defer source.mutex.Unlock()
+	assert.Empty(t, source.consumers, "the provider should not be the consumer of the query")
+}
+
+func TestReplicator_Watch(t *testing.T) {
+	source, sourceClient := newFakeRegistry(t)
+	target, targetClient := newFakeRegistry(t)
+	source.addService(&discovery.MicroService{ServiceId: "s1", AppId: "app", ServiceName: "s1", Version: "1.0.0"})
+	source.addService(&discovery.MicroService{ServiceId: "s2", AppId: "app", ServiceName: "s2", Version: "1.0.0"})
+	source.addInstance(&discovery.MicroServiceInstance{InstanceId: "i1", ServiceId: "s1", Status: "UP",
+		Endpoints: []string{"rest://1.1.1.1:80"}})
+
+	r, err := replicator.New(replicator.Options{
+		Source:            sourceClient,
+		Target:            targetClient,
+		ReconcileInterval: time.Hour,
+		HeartbeatInterval: time.Hour,
+		Logger:            sc.NewOpenlogLogger(),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Start(context.Background()))
+	reconciledAt := r.Status().LastReconcileAt
+
+	services, _ := target.snapshot()
+	assert.Equal(t, map[string]string{"s1": "s1", "s2": "s2"}, services, "the watcher should not be replicated")
+	source.mutex.Lock()
+	assert.Len(t, source.watches, 1, "one watcher should watch all the services")
+	assert.ElementsMatch(t, []string{"/app/s1/1.0.0", "/app/s2/1.0.0"},
+		source.dependencies["/default/"+replicator.DefaultWatcherName+"/1.0.0"])
+	source.mutex.Unlock()
+
+	source.addInstance(&discovery.MicroServiceInstance{InstanceId: "i2", ServiceId: "s2", Status: "UP",
+		Endpoints: []string{"rest://2.2.2.2:80"}})
+	source.deleteInstance("i1")
+	assert.Eventually(t, func() bool {
+		_, instances := target.snapshot()
+		return len(instances) == 1 && instances["i2"] == "UP"
+	}, 3*time.Second, 10*time.Millisecond)
+	assert.Equal(t, reconciledAt, r.Status().LastReconcileAt, "the events should be applied without reconciliation")
+
+	r.Stop()
+	assert.Zero(t, r.Status().Errors, r.Status().LastError)
+	services, _ = source.snapshot()
+	assert.Equal(t, map[string]string{"s1": "s1", "s2": "s2"}, services, "the watcher should be unregistered")
+}
+
+func TestFilter_Match(t *testing.T) {
+	f := replicator.Filter{IncludeApps: []string{"a", "b"}, ExcludeEnvironments: []string{"production"}}
+	assert.True(t, f.Match(&discovery.MicroService{AppId: "a"}))
+	assert.False(t, f.Match(&discovery.MicroService{AppId: "c"}))
+	assert.False(t, f.Match(&discovery.MicroService{AppId: "b", Environment: "production"}))
+	assert.True(t, replicator.Filter{}.Match(&discovery.MicroService{AppId: "c"}))
+}
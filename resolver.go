@@ -0,0 +1,276 @@
+package sc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Define the defaults of the endpoint resolution
+const (
+	DefaultResolveInterval  = 30 * time.Second
+	DefaultFilePollInterval = 5 * time.Second
+)
+
+// EndpointResolver locates the same AZ addresses of service center,
+// the client resolves them when it is created and then every Options.ResolveInterval,
+// the pool is only reset if the set of the addresses changes, so the shuffled answers keep the current order
+type EndpointResolver interface {
+	// Resolve returns the host:port addresses
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// WatchingResolver is a resolver which knows when the addresses change
+type WatchingResolver interface {
+	EndpointResolver
+	// Watch signals the channel when the addresses may change, until ctx is done
+	Watch(ctx context.Context) <-chan struct{}
+}
+
+// StaticResolver returns the fixed addresses
+type StaticResolver []string
+
+// Resolve returns the addresses
+func (r StaticResolver) Resolve(context.Context) ([]string, error) {
+	return append([]string(nil), r...), nil
+}
+
+// DNSResolver looks up the A and AAAA records of Host, the addresses use the same Port
+type DNSResolver struct {
+	Host string
+	Port int
+	// Resolver is net.DefaultResolver if it is nil
+	Resolver *net.Resolver
+}
+
+// Resolve looks up the IP addresses of the host
+func (r *DNSResolver) Resolve(ctx context.Context) ([]string, error) {
+	ips, err := resolverOf(r.Resolver).LookupIPAddr(ctx, r.Host)
+	if err != nil {
+		return nil, err
+	}
+	addresses := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		addresses = append(addresses, net.JoinHostPort(ip.IP.String(), strconv.Itoa(r.Port)))
+	}
+	return addresses, nil
+}
+
+// groupResolver returns the addresses in groups, the order of the groups is significant
+// while the order in a group is not, such as the priorities of the SRV records
+type groupResolver interface {
+	resolveGroups(ctx context.Context) ([][]string, error)
+}
+
+// SRVResolver looks up the SRV records _Service._Proto.Name,
+// the addresses are ordered by priority and weight, a change of the priorities resets the pool
+type SRVResolver struct {
+	Service string
+	Proto   string
+	Name    string
+	// Resolver is net.DefaultResolver if it is nil
+	Resolver *net.Resolver
+}
+
+// Resolve looks up the targets and ports of the service
+func (r *SRVResolver) Resolve(ctx context.Context) ([]string, error) {
+	groups, err := r.resolveGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var addresses []string
+	for _, group := range groups {
+		addresses = append(addresses, group...)
+	}
+	return addresses, nil
+}
+
+// resolveGroups groups the addresses by priority, the records are already sorted by priority
+func (r *SRVResolver) resolveGroups(ctx context.Context) ([][]string, error) {
+	_, records, err := resolverOf(r.Resolver).LookupSRV(ctx, r.Service, r.Proto, r.Name)
+	if err != nil {
+		return nil, err
+	}
+	var groups [][]string
+	for i, srv := range records {
+		if i == 0 || srv.Priority != records[i-1].Priority {
+			groups = append(groups, nil)
+		}
+		address := net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), strconv.Itoa(int(srv.Port)))
+		groups[len(groups)-1] = append(groups[len(groups)-1], address)
+	}
+	return groups, nil
+}
+
+func resolverOf(r *net.Resolver) *net.Resolver {
+	if r == nil {
+		return net.DefaultResolver
+	}
+	return r
+}
+
+// FileResolver reads the addresses from a file, one per line or separated by commas,
+// the empty lines and the lines starting with # are ignored.
+// the file is polled for changes every PollInterval
+type FileResolver struct {
+	Path         string
+	PollInterval time.Duration
+}
+
+// Resolve reads the file
+func (r *FileResolver) Resolve(context.Context) ([]string, error) {
+	b, err := ioutil.ReadFile(r.Path)
+	if err != nil {
+		return nil, NewIOException(err, "read endpoints file %s failed", r.Path)
+	}
+	var addresses []string
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, a := range strings.Split(line, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				addresses = append(addresses, a)
+			}
+		}
+	}
+	return addresses, nil
+}
+
+// Watch signals the channel when the modification time or the size of the file changes
+func (r *FileResolver) Watch(ctx context.Context) <-chan struct{} {
+	interval := r.PollInterval
+	if interval <= 0 {
+		interval = DefaultFilePollInterval
+	}
+	ch := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := r.stat()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			current := r.stat()
+			if current == last {
+				continue
+			}
+			last = current
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return ch
+}
+
+func (r *FileResolver) stat() string {
+	info, err := os.Stat(r.Path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+}
+
+func resolveGroups(ctx context.Context, r EndpointResolver) ([][]string, error) {
+	if g, ok := r.(groupResolver); ok {
+		return g.resolveGroups(ctx)
+	}
+	addresses, err := r.Resolve(ctx)
+	if err != nil || len(addresses) == 0 {
+		return nil, err
+	}
+	return [][]string{addresses}, nil
+}
+
+// resolutionKey identifies the resolution regardless of the order in the groups
+func resolutionKey(groups [][]string) string {
+	keys := make([]string, 0, len(groups))
+	for _, group := range groups {
+		sorted := append([]string(nil), group...)
+		sort.Strings(sorted)
+		keys = append(keys, strings.Join(sorted, ","))
+	}
+	return strings.Join(keys, ";")
+}
+
+// resolveEndpoints resolves the addresses and replaces the same AZ addresses of the pool if they change,
+// an empty result keeps the current addresses
+func (c *Client) resolveEndpoints(ctx context.Context) error {
+	parent := ctx
+	if c.opt.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.opt.Timeout)
+		defer cancel()
+	}
+	groups, err := resolveGroups(ctx, c.opt.EndpointResolver)
+	if err != nil {
+		return err
+	}
+	var addresses []string
+	for _, group := range groups {
+		addresses = append(addresses, group...)
+	}
+	if len(addresses) == 0 {
+		return ErrNoMembers
+	}
+	key := resolutionKey(groups)
+	c.membersMutex.Lock()
+	if err = parent.Err(); err != nil {
+		// the resolver is stopped by Reset while resolving
+		c.membersMutex.Unlock()
+		return err
+	}
+	changed := key != c.resolved
+	if changed {
+		c.static = addresses
+		c.resolved = key
+	}
+	c.membersMutex.Unlock()
+	if changed && c.pool != nil {
+		c.log.Info("service center endpoints resolved", "endpoints", strings.Join(addresses, ","))
+		c.pool.ResetAddress(c.sameAZAddresses())
+	}
+	return nil
+}
+
+// runResolver resolves the addresses every interval and when the resolver tells they change, until ctx is done
+func (c *Client) runResolver(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultResolveInterval
+	}
+	var changes <-chan struct{}
+	if w, ok := c.opt.EndpointResolver.(WatchingResolver); ok {
+		changes = w.Watch(ctx)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-changes:
+		}
+		if err := c.resolveEndpoints(ctx); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.log.Warn("resolve service center endpoints failed", "error", err)
+		}
+	}
+}
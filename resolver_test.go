@@ -0,0 +1,110 @@
+package sc_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestFileResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("# service center\n10.0.0.1:30100\n\n10.0.0.2:30100, 10.0.0.3:30100\n"), 0600))
+	r := &sc.FileResolver{Path: path, PollInterval: 10 * time.Millisecond}
+	addresses, err := r.Resolve(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1:30100", "10.0.0.2:30100", "10.0.0.3:30100"}, addresses)
+
+	t.Run("client should follow the changes of the file", func(t *testing.T) {
+		c, err := sc.NewClient(sc.Options{EndpointResolver: r, ResolveInterval: time.Hour})
+		assert.NoError(t, err)
+		defer c.Close()
+		assert.Equal(t, []string{"10.0.0.1:30100", "10.0.0.2:30100", "10.0.0.3:30100"}, endpointAddresses(c))
+
+		// make sure the modification time changes
+		time.Sleep(20 * time.Millisecond)
+		assert.NoError(t, ioutil.WriteFile(path, []byte("10.0.0.4:30100\n"), 0600))
+		assert.Eventually(t, func() bool {
+			addresses := endpointAddresses(c)
+			return len(addresses) == 1 && addresses[0] == "10.0.0.4:30100"
+		}, 3*time.Second, 10*time.Millisecond)
+
+		// an empty file should not wipe the addresses
+		assert.NoError(t, ioutil.WriteFile(path, []byte("# nothing\n"), 0600))
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, []string{"10.0.0.4:30100"}, endpointAddresses(c))
+	})
+	t.Run("missing file should fail the client without endpoints", func(t *testing.T) {
+		_, err := sc.NewClient(sc.Options{EndpointResolver: &sc.FileResolver{Path: path + ".missing"}})
+		assert.Error(t, err)
+
+		c, err := sc.NewClient(sc.Options{
+			Endpoints:        []string{"127.0.0.1:30100"},
+			EndpointResolver: &sc.FileResolver{Path: path + ".missing"},
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		assert.Equal(t, []string{"127.0.0.1:30100"}, endpointAddresses(c))
+	})
+}
+
+func TestStaticResolver(t *testing.T) {
+	c, err := sc.NewClient(sc.Options{
+		EndpointResolver: sc.StaticResolver{"10.0.0.1:30100"},
+		DiffAzEndpoints:  []string{"10.0.1.1:30100"},
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+	states := c.EndpointStatus()
+	assert.Equal(t, "10.0.0.1:30100", states[0].Address)
+	assert.True(t, states[0].SameAZ)
+	assert.False(t, states[1].SameAZ)
+}
+
+func TestDNSResolver(t *testing.T) {
+	r := &sc.DNSResolver{Host: "localhost", Port: 30100}
+	addresses, err := r.Resolve(context.Background())
+	if err != nil {
+		t.Skip("localhost can not be resolved:", err)
+	}
+	assert.Contains(t, addresses, "127.0.0.1:30100")
+}
+
+// shuffledResolver answers the same addresses in a different order every time
+type shuffledResolver struct {
+	calls int32
+}
+
+func (r *shuffledResolver) Resolve(context.Context) ([]string, error) {
+	if atomic.AddInt32(&r.calls, 1)%2 == 0 {
+		return []string{"10.0.0.2:30100", "10.0.0.1:30100"}, nil
+	}
+	return []string{"10.0.0.1:30100", "10.0.0.2:30100"}, nil
+}
+
+func TestClient_ResolveEndpoints(t *testing.T) {
+	r := &shuffledResolver{}
+	c, err := sc.NewClient(sc.Options{EndpointResolver: r, ResolveInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	t.Run("reordered addresses should keep the pool", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&r.calls) >= 4
+		}, 3*time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"10.0.0.1:30100", "10.0.0.2:30100"}, endpointAddresses(c))
+	})
+	t.Run("reset should stop the resolver", func(t *testing.T) {
+		assert.NoError(t, c.Reset(sc.Options{Endpoints: []string{"10.0.0.3:30100"}}))
+		calls := atomic.LoadInt32(&r.calls)
+		time.Sleep(50 * time.Millisecond)
+		assert.LessOrEqual(t, atomic.LoadInt32(&r.calls), calls+1)
+		assert.Equal(t, []string{"10.0.0.3:30100"}, endpointAddresses(c))
+	})
+}
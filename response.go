@@ -1,6 +1,8 @@
 package sc
 
 import (
+	"time"
+
 	"github.com/go-chassis/cari/discovery"
 )
 
@@ -42,4 +44,8 @@ type MicroServiceInstanceChangedEvent struct {
 type FindMicroServiceInstancesResult struct {
 	Instances []*discovery.MicroServiceInstance
 	Revision  string
+	// Stale means service center is unavailable and the result is served from the snapshot
+	Stale bool
+	// UpdatedAt is the time the stale result was fetched from service center
+	UpdatedAt time.Time
 }
@@ -0,0 +1,57 @@
+package sc
+
+import (
+	"strings"
+	"sync"
+)
+
+type flightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// flightGroup collapses the concurrent calls of the same key into one
+type flightGroup struct {
+	mutex sync.Mutex
+	calls map[string]*flightCall
+}
+
+// do runs fn once for all the concurrent callers of the key, shared reports whether the result is shared
+func (g *flightGroup) do(key string, fn func() (interface{}, error)) (v interface{}, err error, shared bool) {
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mutex.Unlock()
+		call.wg.Wait()
+		return call.val, call.err, true
+	}
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mutex.Unlock()
+
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		call.wg.Done()
+	}()
+	call.val, call.err = fn()
+	return call.val, call.err, false
+}
+
+// flightKey identifies a read by method, url and consumer, the host is included only if it is specified
+func flightKey(method, api string, querys []URLParameter, copts *CallOptions, consumerID string, body []byte) string {
+	builder := URLBuilder{
+		Path:          api,
+		URLParameters: querys,
+		CallOptions:   copts,
+	}
+	if copts != nil {
+		builder.Host = copts.Address
+	}
+	return strings.Join([]string{method, builder.String(), consumerID, string(body)}, "\n")
+}
@@ -0,0 +1,65 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_FindInstancesCollapsing(t *testing.T) {
+	var requests int32
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		time.Sleep(100 * time.Millisecond)
+		if request.Header.Get("X-ConsumerId") == "bad" {
+			writer.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writer.Write([]byte(`{"instances":[{"instanceId":"i1"}]}`))
+	}))
+	defer scServer.Close()
+
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{scServer.Listener.Addr().String()},
+	})
+	assert.NoError(t, err)
+
+	find := func(consumerID string, n int) []error {
+		errs := make([]error, n)
+		wg := sync.WaitGroup{}
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				rst, err := c.FindInstances(consumerID, "default", "provider")
+				errs[i] = err
+				if err == nil {
+					assert.Equal(t, "i1", rst.Instances[0].InstanceId)
+				}
+			}(i)
+		}
+		wg.Wait()
+		return errs
+	}
+	t.Run("identical queries should share one request", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		for _, err := range find("consumer", 10) {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+	t.Run("identical queries should share the error", func(t *testing.T) {
+		atomic.StoreInt32(&requests, 0)
+		for _, err := range find("bad", 10) {
+			assert.Error(t, err)
+		}
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+}
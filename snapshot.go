@@ -0,0 +1,185 @@
+package sc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// Define the defaults of the snapshot
+const (
+	DefaultSnapshotInterval = 30 * time.Second
+	snapshotVersion         = 1
+)
+
+// SnapshotOptions persists the last successful FindInstances results to a local file,
+// they are served as stale results when service center is unavailable
+type SnapshotOptions struct {
+	// Path is the snapshot file, it is loaded when the client is created
+	Path string
+	// Interval is how often the changed results are written to the file
+	Interval time.Duration
+}
+
+type snapshotEntry struct {
+	AppID       string                            `json:"appId"`
+	ServiceName string                            `json:"serviceName"`
+	VersionRule string                            `json:"versionRule"`
+	Revision    string                            `json:"revision,omitempty"`
+	Instances   []*discovery.MicroServiceInstance `json:"instances"`
+	UpdatedAt   time.Time                         `json:"updatedAt"`
+}
+
+type snapshotFile struct {
+	Version int                       `json:"version"`
+	SavedAt time.Time                 `json:"savedAt"`
+	Entries map[string]*snapshotEntry `json:"entries"`
+}
+
+// snapshotStore keeps the provider instances in memory and flushes them to the file
+type snapshotStore struct {
+	mutex   sync.Mutex
+	path    string
+	entries map[string]*snapshotEntry
+	dirty   bool
+	log     Logger
+}
+
+// snapshotKey identifies a query, the version rule is unescaped, e.g. "0+"
+func snapshotKey(appID, serviceName, versionRule string) string {
+	return appID + "/" + serviceName + "/" + versionRule
+}
+
+func newSnapshotStore(opt *SnapshotOptions, log Logger) *snapshotStore {
+	if opt == nil || opt.Path == "" {
+		return nil
+	}
+	s := &snapshotStore{
+		path:    opt.Path,
+		entries: make(map[string]*snapshotEntry),
+		log:     log,
+	}
+	if err := s.load(); err != nil && !os.IsNotExist(err) {
+		log.Warn("load discovery snapshot failed", "path", s.path, "error", err)
+	}
+	return s
+}
+
+func (s *snapshotStore) load() error {
+	b, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+	f := &snapshotFile{}
+	if err = json.Unmarshal(b, f); err != nil {
+		return NewJSONException(err, "invalid snapshot file %s", s.path)
+	}
+	if f.Version != snapshotVersion {
+		return NewCommonException("unsupported snapshot version %d", f.Version)
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for k, e := range f.Entries {
+		s.entries[k] = e
+	}
+	s.log.Info("discovery snapshot loaded", "path", s.path, "entries", len(f.Entries), "savedAt", f.SavedAt)
+	return nil
+}
+
+func (s *snapshotStore) put(appID, serviceName, versionRule string, rst *FindMicroServiceInstancesResult) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.entries[snapshotKey(appID, serviceName, versionRule)] = &snapshotEntry{
+		AppID:       appID,
+		ServiceName: serviceName,
+		VersionRule: versionRule,
+		Revision:    rst.Revision,
+		Instances:   rst.Instances,
+		UpdatedAt:   time.Now(),
+	}
+	s.dirty = true
+}
+
+// get returns the stale result of the provider
+func (s *snapshotStore) get(appID, serviceName, versionRule string) (*FindMicroServiceInstancesResult, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	e, ok := s.entries[snapshotKey(appID, serviceName, versionRule)]
+	if !ok {
+		return nil, false
+	}
+	return &FindMicroServiceInstancesResult{
+		Instances: e.Instances,
+		Revision:  e.Revision,
+		Stale:     true,
+		UpdatedAt: e.UpdatedAt,
+	}, true
+}
+
+// flush writes the entries to the file if they are changed, the file is replaced atomically
+func (s *snapshotStore) flush() error {
+	s.mutex.Lock()
+	if !s.dirty {
+		s.mutex.Unlock()
+		return nil
+	}
+	b, err := json.Marshal(&snapshotFile{
+		Version: snapshotVersion,
+		SavedAt: time.Now(),
+		Entries: s.entries,
+	})
+	s.dirty = false
+	s.mutex.Unlock()
+	if err != nil {
+		return NewJSONException(err, "marshal snapshot failed")
+	}
+	if err = s.write(b); err != nil {
+		// try again in the next round
+		s.mutex.Lock()
+		s.dirty = true
+		s.mutex.Unlock()
+		return NewIOException(err, "write snapshot %s failed", s.path)
+	}
+	return nil
+}
+
+func (s *snapshotStore) write(b []byte) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// run flushes the snapshot every interval until the context is done
+func (s *snapshotStore) run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultSnapshotInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.flush(); err != nil {
+				s.log.Error("persist discovery snapshot failed", "path", s.path, "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package sc_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestOptions_Snapshot(t *testing.T) {
+	var down int32
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if atomic.LoadInt32(&down) == 1 {
+			writer.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writer.Header().Set(sc.HeaderRevision, "rev1")
+		writer.Write([]byte(`{"instances":[{"instanceId":"i1"}]}`))
+	}))
+	defer scServer.Close()
+	opt := sc.Options{
+		Endpoints: []string{scServer.Listener.Addr().String()},
+		Snapshot:  &sc.SnapshotOptions{Path: filepath.Join(t.TempDir(), "snapshot.json")},
+	}
+
+	c, err := sc.NewClient(opt)
+	assert.NoError(t, err)
+	rst, err := c.FindInstances("", "default", "provider")
+	assert.NoError(t, err)
+	assert.False(t, rst.Stale)
+	assert.NoError(t, c.Close())
+	saved, err := ioutil.ReadFile(opt.Snapshot.Path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(saved), `"default/provider/0+"`, "the version rule should be saved unescaped")
+
+	atomic.StoreInt32(&down, 1)
+	t.Run("outage on startup should serve the persisted instances", func(t *testing.T) {
+		c, err := sc.NewClient(opt)
+		assert.NoError(t, err)
+		defer c.Close()
+		rst, err := c.FindInstances("", "default", "provider")
+		assert.NoError(t, err)
+		assert.True(t, rst.Stale)
+		assert.Equal(t, "rev1", rst.Revision)
+		assert.Equal(t, "i1", rst.Instances[0].InstanceId)
+		assert.False(t, rst.UpdatedAt.IsZero())
+	})
+	t.Run("unknown provider should still fail", func(t *testing.T) {
+		c, err := sc.NewClient(opt)
+		assert.NoError(t, err)
+		defer c.Close()
+		_, err = c.FindInstances("", "default", "unknown")
+		assert.Error(t, err)
+	})
+}
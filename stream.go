@@ -0,0 +1,191 @@
+package sc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/go-chassis/cari/discovery"
+)
+
+// ErrResponseTooLarge means the response body exceeds Options.MaxResponseSize
+var ErrResponseTooLarge = errors.New("response body is too large")
+
+// limitedReader fails with ErrResponseTooLarge instead of truncating the body
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
+}
+
+// bodyReader returns the response body limited by MaxResponseSize
+func (c *Client) bodyReader(resp *http.Response) io.Reader {
+	if c.opt.MaxResponseSize <= 0 {
+		return resp.Body
+	}
+	return &limitedReader{r: resp.Body, n: c.opt.MaxResponseSize}
+}
+
+// readBody reads the whole body limited by MaxResponseSize, it is used for the error responses
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	return ioutil.ReadAll(c.bodyReader(resp))
+}
+
+// decodeBody decodes the json body without buffering all of it
+func (c *Client) decodeBody(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	err := json.NewDecoder(c.bodyReader(resp)).Decode(v)
+	if errors.Is(err, ErrResponseTooLarge) {
+		return NewIOException(err, "limit is %d bytes", c.opt.MaxResponseSize)
+	}
+	if err != nil {
+		return NewJSONException(err, "decode response body failed")
+	}
+	return nil
+}
+
+// eachElement decodes the elements of the array field of the top level json object one by one,
+// other fields are skipped
+func eachElement(r io.Reader, field string, next func(dec *json.Decoder) error) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key, _ := t.(string); key != field {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+			continue
+		}
+		// null means an empty array
+		t, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		if t == nil {
+			continue
+		}
+		if d, ok := t.(json.Delim); !ok || d != '[' {
+			return fmt.Errorf("field %s is not an array", field)
+		}
+		for dec.More() {
+			if err := next(dec); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := t.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("expect %s, got %v", delim, t)
+	}
+	return nil
+}
+
+// streamElements sends the GET request and decodes the elements of the array field one by one,
+// the error of fn is returned as it is
+func (c *Client) streamElements(api string, querys []URLParameter, field string,
+	decode func(dec *json.Decoder) (interface{}, error), fn func(v interface{}) error, opts ...CallOption) error {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	url := c.formatURL(api, querys, copts)
+	resp, err := c.httpDo("GET", url, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return fmt.Errorf("query %s failed, response is empty", field)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := c.readBody(resp)
+		if err != nil {
+			return NewIOException(err)
+		}
+		return fmt.Errorf("query %s failed, response StatusCode: %d, response body: %s", field, resp.StatusCode, string(body))
+	}
+	defer resp.Body.Close()
+	var fnErr error
+	err = eachElement(c.bodyReader(resp), field, func(dec *json.Decoder) error {
+		v, err := decode(dec)
+		if err != nil {
+			return err
+		}
+		fnErr = fn(v)
+		return fnErr
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	if errors.Is(err, ErrResponseTooLarge) {
+		return NewIOException(err, "limit is %d bytes", c.opt.MaxResponseSize)
+	}
+	if err != nil {
+		return NewJSONException(err, "decode %s failed", field)
+	}
+	return nil
+}
+
+// EachService calls fn with every micro service registered in service center,
+// the services are decoded one by one instead of materializing the whole list.
+// it stops at the first error returned by fn
+func (c *Client) EachService(fn func(*discovery.MicroService) error, opts ...CallOption) error {
+	return c.streamElements(MSAPIPath+MicroservicePath, nil, "services", func(dec *json.Decoder) (interface{}, error) {
+		service := &discovery.MicroService{}
+		err := dec.Decode(service)
+		return service, err
+	}, func(v interface{}) error {
+		return fn(v.(*discovery.MicroService))
+	}, opts...)
+}
+
+// EachServiceDetail calls fn with the detail of every micro service from the governance API,
+// use WithGovernOptions to select what is returned with the services, the same as GetServiceDetails
+func (c *Client) EachServiceDetail(fn func(*discovery.ServiceDetail) error, opts ...CallOption) error {
+	copts := &CallOptions{}
+	for _, opt := range opts {
+		opt(copts)
+	}
+	return c.streamElements(GovernAPIPATH+MicroservicePath, governQuery(copts.GovernOptions),
+		"allServicesDetail", func(dec *json.Decoder) (interface{}, error) {
+			detail := &discovery.ServiceDetail{}
+			err := dec.Decode(detail)
+			return detail, err
+		}, func(v interface{}) error {
+			return fn(v.(*discovery.ServiceDetail))
+		}, opts...)
+}
@@ -0,0 +1,58 @@
+package sc_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chassis/cari/discovery"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestClient_EachService(t *testing.T) {
+	scServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"extra":{"a":[1,2]},"services":[{"serviceId":"s1"},{"serviceId":"s2"},{"serviceId":"s3"}]}`))
+	}))
+	defer scServer.Close()
+
+	c, err := sc.NewClient(sc.Options{
+		Endpoints: []string{scServer.Listener.Addr().String()},
+	})
+	assert.NoError(t, err)
+
+	t.Run("should visit every service", func(t *testing.T) {
+		var ids []string
+		err := c.EachService(func(s *discovery.MicroService) error {
+			ids = append(ids, s.ServiceId)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"s1", "s2", "s3"}, ids)
+	})
+	t.Run("should stop at the error of callback", func(t *testing.T) {
+		stop := errors.New("stop")
+		var ids []string
+		err := c.EachService(func(s *discovery.MicroService) error {
+			ids = append(ids, s.ServiceId)
+			return stop
+		})
+		assert.Equal(t, stop, err)
+		assert.Equal(t, []string{"s1"}, ids)
+	})
+	t.Run("response exceeding the limit should fail", func(t *testing.T) {
+		limited, err := sc.NewClient(sc.Options{
+			Endpoints:       []string{scServer.Listener.Addr().String()},
+			MaxResponseSize: 32,
+		})
+		assert.NoError(t, err)
+		_, err = limited.GetAllMicroServices()
+		assert.ErrorIs(t, err, sc.ErrResponseTooLarge)
+		err = limited.EachService(func(s *discovery.MicroService) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, sc.ErrResponseTooLarge)
+	})
+}
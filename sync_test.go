@@ -0,0 +1,69 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func endpointAddresses(c *sc.Client) []string {
+	var addresses []string
+	for _, s := range c.EndpointStatus() {
+		addresses = append(addresses, s.Address)
+	}
+	return addresses
+}
+
+func TestClient_SyncEndpointsMerge(t *testing.T) {
+	var members atomic.Value
+	members.Store(`[]`)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/v4/default/registry/health", request.URL.Path)
+		writer.Write([]byte(`{"instances":` + members.Load().(string) + `}`))
+	}))
+	defer server.Close()
+	addr := server.Listener.Addr().String()
+
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{addr}, DiffAzEndpoints: []string{"10.0.0.9:30100"}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	t.Run("empty members should keep the endpoints", func(t *testing.T) {
+		assert.Equal(t, sc.ErrNoMembers, c.SyncEndpoints())
+		assert.Equal(t, []string{addr, "10.0.0.9:30100"}, endpointAddresses(c))
+	})
+	t.Run("members should be merged with the static endpoints", func(t *testing.T) {
+		members.Store(`[{"endpoints":["rest://10.0.0.1:30100?sslEnabled=false","rest://` + addr + `"]},` +
+			`{"endpoints":["grpc://10.0.0.2:30110","rest://10.0.0.2:30100","syncer://10.0.0.2:30190"]}]`)
+		assert.NoError(t, c.SyncEndpoints())
+		assert.Equal(t, []string{addr, "10.0.0.1:30100", "10.0.0.2:30100", "10.0.0.9:30100"}, endpointAddresses(c))
+	})
+}
+
+func TestOptions_SyncEndpointsInterval(t *testing.T) {
+	var queries int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		atomic.AddInt32(&queries, 1)
+		writer.Write([]byte(`{"instances":[{"endpoints":["rest://10.0.0.1:30100"]}]}`))
+	}))
+	defer server.Close()
+	addr := server.Listener.Addr().String()
+
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{addr}, SyncEndpointsInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return len(endpointAddresses(c)) == 2
+	}, 3*time.Second, 10*time.Millisecond)
+
+	assert.NoError(t, c.Close())
+	time.Sleep(30 * time.Millisecond)
+	stopped := atomic.LoadInt32(&queries)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&queries))
+}
@@ -0,0 +1,54 @@
+package sc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chassis/foundation/httpclient"
+	"github.com/gorilla/websocket"
+)
+
+// Transport sends the requests of the client to service center, it can be replaced by Options.Transport.
+// the unary requests are signed by the transport, the websocket handshake headers are signed by the client
+type Transport interface {
+	// Do sends a unary request
+	Do(ctx context.Context, method, rawURL string, headers http.Header, body []byte) (*http.Response, error)
+	// DialWebsocket opens a websocket connection for watching and heartbeat
+	DialWebsocket(ctx context.Context, rawURL string, headers http.Header) (*websocket.Conn, *http.Response, error)
+}
+
+// defaultTransport sends the unary requests by httpclient.Requests, which compresses and signs them
+type defaultTransport struct {
+	*httpclient.Requests
+	dialer *websocket.Dialer
+}
+
+// DialWebsocket dials with the websocket dialer
+func (t *defaultTransport) DialWebsocket(ctx context.Context, rawURL string,
+	headers http.Header) (*websocket.Conn, *http.Response, error) {
+	return t.dialer.DialContext(ctx, rawURL, headers)
+}
+
+// newTransport returns Options.Transport, or builds the default one
+func (c *Client) newTransport(opt Options) (Transport, error) {
+	if opt.Transport != nil {
+		return opt.Transport, nil
+	}
+	requests, err := httpclient.New(c.buildClientOptions(opt))
+	if err != nil {
+		return nil, err
+	}
+	if opt.RoundTripper != nil {
+		requests.Client.Transport = opt.RoundTripper
+	}
+	dialer := opt.WebsocketDialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+		if opt.EnableSSL {
+			dialer = &websocket.Dialer{
+				TLSClientConfig: opt.TLSConfig,
+			}
+		}
+	}
+	return &defaultTransport{Requests: requests, dialer: dialer}, nil
+}
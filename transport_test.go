@@ -0,0 +1,84 @@
+package sc_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+type fakeTransport struct {
+	urls []string
+}
+
+func (t *fakeTransport) Do(ctx context.Context, method, rawURL string, headers http.Header, body []byte) (*http.Response, error) {
+	t.urls = append(t.urls, method+" "+rawURL)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(`{"appIds":["app"]}`)),
+	}, nil
+}
+
+func (t *fakeTransport) DialWebsocket(ctx context.Context, rawURL string, headers http.Header) (*websocket.Conn, *http.Response, error) {
+	t.urls = append(t.urls, "WS "+rawURL)
+	return nil, nil, errors.New("no websocket")
+}
+
+func TestOptions_Transport(t *testing.T) {
+	transport := &fakeTransport{}
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{"127.0.0.1:30100"}, Transport: transport})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	apps, err := c.GetAllApplications()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app"}, apps)
+	err = c.WatchMicroService("serviceID", func(*sc.MicroServiceInstanceChangedEvent) {})
+	assert.Error(t, err)
+	assert.Equal(t, []string{
+		"GET http://127.0.0.1:30100/v4/default/govern/apps",
+		"WS ws://127.0.0.1:30100/v4/default/registry/microservices/serviceID/watcher",
+	}, transport.urls)
+}
+
+type countingRoundTripper struct {
+	requests int
+	auth     string
+}
+
+func (rt *countingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests++
+	rt.auth = req.Header.Get("Authorization")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOptions_RoundTripper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.Write([]byte(`{"appIds":["app"]}`))
+	}))
+	defer server.Close()
+	rt := &countingRoundTripper{}
+	c, err := sc.NewClient(sc.Options{
+		Endpoints:    []string{server.Listener.Addr().String()},
+		EnableAuth:   true,
+		AuthToken:    "token",
+		RoundTripper: rt,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.GetAllApplications()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rt.requests)
+	// the default transport still signs the requests
+	assert.Equal(t, "Bearer token", rt.auth)
+}
@@ -0,0 +1,101 @@
+package sc
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// VersionRule selects the versions of the providers in the instance queries,
+// it is not escaped, the URL builder escapes it
+type VersionRule string
+
+// Define the special version rules
+const (
+	// VersionRuleLatest is the latest version of the service
+	VersionRuleLatest VersionRule = "latest"
+	// VersionRuleAll is every version of the service
+	VersionRuleAll VersionRule = "0+"
+)
+
+// VersionLatest returns the rule of the latest version
+func VersionLatest() VersionRule {
+	return VersionRuleLatest
+}
+
+// VersionExact returns the rule of the version, for example 1.0.0
+func VersionExact(version string) VersionRule {
+	return VersionRule(version)
+}
+
+// VersionRange returns the rule of the versions from the first one up to the second one, for example 1.0.0-2.0.0
+func VersionRange(from, to string) VersionRule {
+	return VersionRule(from + "-" + to)
+}
+
+// VersionMin returns the rule of the version and the later ones, for example 1.2.0+
+func VersionMin(version string) VersionRule {
+	return VersionRule(version + "+")
+}
+
+// ParseVersionRule validates the rule
+func ParseVersionRule(s string) (VersionRule, error) {
+	r := VersionRule(strings.TrimSpace(s))
+	return r, r.Validate()
+}
+
+func (r VersionRule) String() string {
+	return string(r)
+}
+
+// Validate checks the rule is latest, an exact version, a range or a minimum version,
+// a version has one to four numeric parts, such as 1, 1.0 or 1.0.0.0
+func (r VersionRule) Validate() error {
+	s := string(r)
+	switch {
+	case r == VersionRuleLatest:
+		return nil
+	case strings.HasSuffix(s, "+"):
+		return validateVersion(r, strings.TrimSuffix(s, "+"))
+	case strings.Contains(s, "-"):
+		parts := strings.SplitN(s, "-", 2)
+		if err := validateVersion(r, parts[0]); err != nil {
+			return err
+		}
+		return validateVersion(r, parts[1])
+	}
+	return validateVersion(r, s)
+}
+
+func validateVersion(r VersionRule, version string) error {
+	parts := strings.Split(version, ".")
+	if version == "" || len(parts) > 4 {
+		return fmt.Errorf("invalid version rule %q", string(r))
+	}
+	for _, p := range parts {
+		if n, err := strconv.ParseUint(p, 10, 15); err != nil || strconv.FormatUint(n, 10) != p {
+			return fmt.Errorf("invalid version rule %q", string(r))
+		}
+	}
+	return nil
+}
+
+// WithVersionRule queries the instances of the versions selected by the rule, default is VersionRuleAll
+func WithVersionRule(rule VersionRule) CallOption {
+	return func(o *CallOptions) {
+		o.VersionRule = rule
+	}
+}
+
+// normalizeVersionRule unescapes the rule escaped by the caller, such as 0%2B,
+// so it is escaped only once by the URL builder
+func normalizeVersionRule(rule string) string {
+	if !strings.Contains(rule, "%") {
+		return rule
+	}
+	if unescaped, err := url.PathUnescape(rule); err == nil {
+		return unescaped
+	}
+	return rule
+}
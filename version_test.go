@@ -0,0 +1,63 @@
+package sc_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-chassis/sc-client"
+)
+
+func TestVersionRule_Validate(t *testing.T) {
+	for _, r := range []sc.VersionRule{
+		sc.VersionLatest(),
+		sc.VersionRuleAll,
+		sc.VersionExact("1.0.0"),
+		sc.VersionExact("1.0.0.1"),
+		sc.VersionRange("1.0.0", "2.0.0"),
+		sc.VersionMin("1.2.0"),
+	} {
+		assert.NoError(t, r.Validate(), r)
+	}
+	for _, s := range []string{"", "+", "1.0.0-", "-1.0.0", "1.0.0.0.0", "1.a", "01.0", "1.0.0%2B", "99999.0"} {
+		_, err := sc.ParseVersionRule(s)
+		assert.Error(t, err, s)
+	}
+	r, err := sc.ParseVersionRule(" 1.0.0+ ")
+	assert.NoError(t, err)
+	assert.Equal(t, sc.VersionMin("1.0.0"), r)
+}
+
+func TestClient_FindInstancesVersionRule(t *testing.T) {
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		rawQuery = request.URL.RawQuery
+		writer.Write([]byte(`{"instances":[]}`))
+	}))
+	defer server.Close()
+	c, err := sc.NewClient(sc.Options{Endpoints: []string{server.Listener.Addr().String()}})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.FindInstances("", "app", "service")
+	assert.NoError(t, err)
+	assert.Contains(t, rawQuery, "version=0%2B")
+
+	_, err = c.FindInstances("", "app", "service", sc.WithVersionRule(sc.VersionMin("1.2.0")))
+	assert.NoError(t, err)
+	assert.Contains(t, rawQuery, "version=1.2.0%2B")
+
+	_, err = c.FindInstances("", "app", "service", sc.WithVersionRule(sc.VersionRange("1.0.0", "2.0.0")))
+	assert.NoError(t, err)
+	assert.Contains(t, rawQuery, "version=1.0.0-2.0.0")
+
+	_, err = c.FindInstances("", "app", "service", sc.WithVersionRule("1.x"))
+	assert.Error(t, err)
+
+	// the escaped rule of the deprecated API is escaped only once
+	_, err = c.FindMicroServiceInstances("", "app", "service", "0%2B")
+	assert.NoError(t, err)
+	assert.Contains(t, rawQuery, "version=0%2B")
+}